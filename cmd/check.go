@@ -19,9 +19,9 @@ var checkCmd = &cobra.Command{
 	No database connection is performed at all.
 	Used for testing new and changed store crawlers.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		crawler := crawler.GetCrawler()
+		crawler := crawler.GetCrawler(crawler.Options{})
 
-		if err := crawler.Test(dump, adidas.GetAdidasStore()); err != nil {
+		if err := crawler.Test(dump, adidas.GetAdidasStore(adidas.Options{MaxReviews: maxReviews})); err != nil {
 			slog.Error("Error at checking crawler", "cause", err)
 		}
 	},
@@ -30,4 +30,5 @@ var checkCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(checkCmd)
 	checkCmd.Flags().IntVarP(&dump, "dump", "d", 0, "dump limit")
+	checkCmd.Flags().IntVar(&maxReviews, "max-reviews", adidas.DefaultMaxReviews, "maximum number of reviews crawled from Bazaarvoice per product, 0 to keep the reviews of the product API")
 }
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"log/slog"
+	"os"
+
+	"vcrawler/internal/diff"
+
+	"github.com/spf13/cobra"
+)
+
+var diffOutput string
+
+// diffCmd represents the diff command
+var diffCmd = &cobra.Command{
+	Use:   "diff old.json new.json",
+	Short: "Compares two crawl snapshots.",
+	Long: `Compares two products.json snapshots, matching the products by article code.
+	Reports the added and removed products, price changes, SKU stock flips,
+	new reviews, rating changes and image set changes.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		oldProducts, err := diff.LoadSnapshot(args[0])
+		if err != nil {
+			slog.Error("Error at loading old snapshot", "cause", err)
+			return
+		}
+
+		newProducts, err := diff.LoadSnapshot(args[1])
+		if err != nil {
+			slog.Error("Error at loading new snapshot", "cause", err)
+			return
+		}
+
+		if err := diff.Write(os.Stdout, diffOutput, diff.Compare(oldProducts, newProducts)); err != nil {
+			slog.Error("Error at writing diff", "cause", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+	diffCmd.Flags().StringVarP(&diffOutput, "output", "o", diff.OutputText, "output format: text, json or csv")
+}
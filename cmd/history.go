@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"vcrawler/internal/dto"
+	"vcrawler/internal/history"
+
+	"github.com/spf13/cobra"
+)
+
+const historyTimeFormat = "2006-01-02 15:04"
+
+// historyCmd represents the history command
+var historyCmd = &cobra.Command{
+	Use:   "history <article>",
+	Short: "Prints the price history of an article.",
+	Long: `Prints the price timeline of an article recorded by the previous runs,
+	the periods where it was discounted and the availability changes of its sizes.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		observations, err := history.Load(historyDir, args[0])
+		if err != nil {
+			slog.Error("Error at loading price history", "cause", err)
+			return
+		}
+
+		fmt.Printf("%s: %d observations\n", args[0], len(observations))
+
+		fmt.Println("\nPrice")
+		for _, point := range history.Timeline(observations) {
+			fmt.Printf("  %s  %s  %10s %s  (without tax %s)  %s\n", formatHistoryTime(point.From), formatHistoryTime(point.To), dto.FormatYen(point.Price.WithTax), point.Price.Currency, dto.FormatYen(point.Price.WithoutTax), point.Price.DiscountType)
+		}
+
+		fmt.Println("\nDiscounts")
+		periods := history.DiscountPeriods(observations)
+		if len(periods) == 0 {
+			fmt.Println("  never discounted")
+		}
+		for _, period := range periods {
+			to := formatHistoryTime(period.To)
+			if period.Ongoing {
+				to += " (ongoing)"
+			}
+			fmt.Printf("  %s  %s  %s\n", formatHistoryTime(period.From), to, period.DiscountType)
+		}
+
+		fmt.Println("\nSizes")
+		for _, change := range history.SkuChanges(observations) {
+			if change.Removed {
+				fmt.Printf("  %s  %-8s removed\n", formatHistoryTime(change.At), change.SizeName)
+				continue
+			}
+			fmt.Printf("  %s  %-8s stock %-5t store %-5t sold out %t\n", formatHistoryTime(change.At), change.SizeName, change.Status.IsStockEc, change.Status.IsStockStore, change.Status.IsSoldOut)
+		}
+	},
+}
+
+func formatHistoryTime(t time.Time) string {
+	return t.Local().Format(historyTimeFormat)
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+	historyCmd.Flags().StringVar(&historyDir, "history-dir", history.DefaultDir, "directory of the price history")
+}
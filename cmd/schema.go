@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"fmt"
+
+	"vcrawler/internal/schema"
+
+	"github.com/spf13/cobra"
+)
+
+// schemaCmd represents the schema command
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Prints the JSON Schema of the crawled products.",
+	Long: `Prints the versioned JSON Schema of the products written to products.json,
+	the contract for the downstream consumers.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Print(string(schema.Product))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+}
@@ -1,24 +1,202 @@
 package cmd
 
 import (
+	"cmp"
+	"fmt"
 	"log/slog"
+	"os"
+	"time"
 
+	"vcrawler/internal/bus"
 	"vcrawler/internal/crawler"
+	"vcrawler/internal/definition"
+	"vcrawler/internal/dto"
+	"vcrawler/internal/export"
+	"vcrawler/internal/history"
+	"vcrawler/internal/notify"
 	"vcrawler/internal/stores/adidas"
+	"vcrawler/internal/upload"
 
 	"github.com/spf13/cobra"
 )
 
+var (
+	formats        []string
+	csvMultiValue  string
+	csvMaxItems    int
+	csvColumns     string
+	csvEncoding    string
+	csvFallback    string
+	csvStripBr     bool
+	esURL          string
+	esIndex        string
+	esUsername     string
+	esPassword     string
+	s3Endpoint     string
+	s3Bucket       string
+	s3Region       string
+	s3AccessKey    string
+	s3SecretKey    string
+	s3Insecure     bool
+	webhookURLs    []string
+	webhookSecret  string
+	webhookRetries int
+	notifyEvents   []string
+	smtpHost       string
+	smtpPort       int
+	smtpUsername   string
+	smtpPassword   string
+	smtpFrom       string
+	smtpTo         []string
+	natsURL        string
+	busPrefix      string
+	validate       bool
+	historyDir     string
+	imagesDir      string
+	imagesWorkers  int
+	imagesDelay    time.Duration
+	variants       bool
+	maxReviews     int
+)
+
 // startCmd represents the start command
 var startCmd = &cobra.Command{
 	Use:   "start",
 	Short: "Starts the crawler",
 	Long:  `Starts the crawler to crawl the store data`,
 	Run: func(cmd *cobra.Command, args []string) {
-		crawler := crawler.GetCrawler()
+		columns, err := export.GetCsvColumns(csvColumns)
+		if err != nil {
+			slog.Error("Error at loading csv columns", "cause", err)
+			return
+		}
+
+		encoding, err := export.GetCsvEncoding(csvEncoding)
+		if err != nil {
+			slog.Error("Error at loading csv encoding", "cause", err)
+			return
+		}
+
+		store := adidas.GetAdidasStore(adidas.Options{MaxReviews: maxReviews})
+
+		var exporters []definition.Exporter
+		for _, format := range formats {
+			switch format {
+			case "csv":
+				exporter, err := export.GetCsvExporter("products.csv", export.CsvOptions{
+					CsvOptions: dto.CsvOptions{
+						MultiValue: dto.CsvMultiValue(csvMultiValue),
+						MaxItems:   csvMaxItems,
+						Columns:    columns,
+					},
+					Encoding:    encoding,
+					Fallback:    csvFallback,
+					StripBreaks: csvStripBr,
+				})
+				if err != nil {
+					slog.Error("Error at loading csv fallback", "cause", err)
+					return
+				}
+				exporters = append(exporters, exporter)
+			case "json":
+				exporters = append(exporters, export.GetJsonExporter("products.json"))
+			case "xlsx":
+				exporters = append(exporters, export.GetXlsxExporter("products.xlsx"))
+			case "parquet":
+				exporters = append(exporters, export.GetParquetExporter("products.parquet"))
+			case "elastic":
+				exporters = append(exporters, export.GetElasticExporter(export.ElasticOptions{
+					URL:      esURL,
+					File:     "products.bulk.ndjson",
+					Index:    esIndex,
+					Username: esUsername,
+					Password: cmp.Or(esPassword, os.Getenv("ES_PASSWORD")),
+				}))
+			case "models":
+				exporters = append(exporters, export.GetModelsExporter("models.json"))
+			case "images":
+				exporters = append(exporters, export.GetImagesExporter(store, export.ImagesOptions{
+					Dir:         imagesDir,
+					Concurrency: imagesWorkers,
+					Delay:       imagesDelay,
+				}))
+			default:
+				slog.Error("Error at loading output formats", "cause", fmt.Errorf("unknown output format: %q", format))
+				return
+			}
+		}
+
+		if historyDir != "" {
+			exporters = append(exporters, history.GetRecorder(historyDir))
+		}
+
+		var uploaders []definition.Uploader
+		if s3Bucket != "" {
+			uploader, err := upload.GetS3Uploader(upload.S3Options{
+				Endpoint:  s3Endpoint,
+				Bucket:    s3Bucket,
+				Region:    s3Region,
+				AccessKey: cmp.Or(s3AccessKey, os.Getenv("AWS_ACCESS_KEY_ID")),
+				SecretKey: cmp.Or(s3SecretKey, os.Getenv("AWS_SECRET_ACCESS_KEY")),
+				Insecure:  s3Insecure,
+			})
+			if err != nil {
+				slog.Error("Error at loading s3 uploader", "cause", err)
+				return
+			}
+			uploaders = append(uploaders, uploader)
+		}
+
+		var publishers []definition.Publisher
+
+		var events []dto.RunStatus
+		for _, event := range notifyEvents {
+			events = append(events, dto.RunStatus(event))
+		}
+
+		if len(webhookURLs) > 0 {
+			publishers = append(publishers, notify.GetWebhookNotifier(notify.WebhookOptions{
+				URLs:    webhookURLs,
+				Secret:  cmp.Or(webhookSecret, os.Getenv("WEBHOOK_SECRET")),
+				Events:  events,
+				Retries: webhookRetries,
+			}))
+		}
+
+		if smtpHost != "" {
+			publishers = append(publishers, notify.GetEmailNotifier(notify.SMTPOptions{
+				Host:     smtpHost,
+				Port:     smtpPort,
+				Username: smtpUsername,
+				Password: cmp.Or(smtpPassword, os.Getenv("SMTP_PASSWORD")),
+				From:     smtpFrom,
+				To:       smtpTo,
+				Events:   events,
+			}))
+		}
+
+		var eventBus definition.EventBus
+		if natsURL != "" {
+			eventBus, err = bus.GetNatsBus(natsURL)
+			if err != nil {
+				slog.Error("Error at connecting to nats", "cause", err)
+				return
+			}
+			defer eventBus.Close()
+		}
+
+		crawler := crawler.GetCrawler(crawler.Options{
+			Exporters:  exporters,
+			Uploaders:  uploaders,
+			Publishers: publishers,
+			Bus:        eventBus,
+			BusPrefix:  busPrefix,
+			Validate:   validate,
+			Variants:   variants,
+		})
 
 		slog.Info("Starting api crawler")
-		if err := crawler.Start(adidas.GetAdidasStore()); err != nil {
+		if err := crawler.Start(store); err != nil {
 			slog.Error("Error at starting api crawler", "cause", err)
 		}
 	},
@@ -26,4 +204,40 @@ var startCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(startCmd)
+	startCmd.Flags().IntVar(&maxReviews, "max-reviews", adidas.DefaultMaxReviews, "maximum number of reviews crawled from Bazaarvoice per product, 0 to keep the reviews of the product API")
+	startCmd.Flags().BoolVar(&variants, "variants", false, "also crawl the other colorways of the models of the crawled products")
+	startCmd.Flags().BoolVar(&validate, "validate", false, "validate the products against the product JSON Schema before writing them")
+	startCmd.Flags().StringVar(&historyDir, "history-dir", history.DefaultDir, "directory to record the price history of the products in, no history when empty")
+	startCmd.Flags().StringSliceVarP(&formats, "format", "f", []string{"csv", "json"}, "output formats: csv, json, xlsx, parquet, elastic, images, models")
+	startCmd.Flags().StringVar(&csvMultiValue, "csv-multi-value", string(dto.CsvMultiValueFirst), "csv strategy for multi-valued fields: first, indexed or json")
+	startCmd.Flags().StringVar(&csvColumns, "csv-columns", export.CsvColumnsTechnicalTest, "csv column preset (all, technical-test, ja) or path to a JSON column mapping file")
+	startCmd.Flags().IntVar(&csvMaxItems, "csv-max-items", 5, "number of indexed csv columns per multi-valued field")
+	startCmd.Flags().StringVar(&csvEncoding, "csv-encoding", string(export.CsvEncodingUTF8), "csv encoding: utf-8, utf-8-bom or shift_jis (cp932)")
+	startCmd.Flags().StringVar(&csvFallback, "csv-fallback", export.DefaultCsvFallback, "replacement for characters that can't be encoded in shift_jis")
+	startCmd.Flags().BoolVar(&csvStripBr, "csv-strip-br", false, "replace the <br /> tags of the general description with new lines")
+	startCmd.Flags().StringVar(&imagesDir, "images-dir", export.DefaultImagesDir, "directory of the images format, with the manifest.json of the local paths by article code")
+	startCmd.Flags().IntVar(&imagesWorkers, "images-concurrency", export.DefaultImagesConcurrency, "parallel image downloads")
+	startCmd.Flags().DurationVar(&imagesDelay, "images-delay", export.DefaultImagesDelay, "minimum delay between two image downloads")
+	startCmd.Flags().StringVar(&esURL, "es-url", "", "Elasticsearch/OpenSearch URL of the elastic format, writes products.bulk.ndjson when empty")
+	startCmd.Flags().StringVar(&esIndex, "es-index", "products", "Elasticsearch/OpenSearch index of the elastic format")
+	startCmd.Flags().StringVar(&esUsername, "es-username", "", "Elasticsearch/OpenSearch basic auth username")
+	startCmd.Flags().StringVar(&esPassword, "es-password", "", "Elasticsearch/OpenSearch basic auth password, defaults to $ES_PASSWORD")
+	startCmd.Flags().StringVar(&s3Endpoint, "s3-endpoint", "s3.amazonaws.com", "S3-compatible endpoint to upload the outputs to, e.g. localhost:9000 for a local MinIO")
+	startCmd.Flags().StringVar(&s3Bucket, "s3-bucket", "", "S3 bucket to upload the outputs, run report and failure manifest to, no upload when empty")
+	startCmd.Flags().StringVar(&s3Region, "s3-region", "", "S3 region")
+	startCmd.Flags().StringVar(&s3AccessKey, "s3-access-key", "", "S3 access key, defaults to $AWS_ACCESS_KEY_ID")
+	startCmd.Flags().StringVar(&s3SecretKey, "s3-secret-key", "", "S3 secret key, defaults to $AWS_SECRET_ACCESS_KEY")
+	startCmd.Flags().BoolVar(&s3Insecure, "s3-insecure", false, "use http for the S3 endpoint")
+	startCmd.Flags().StringSliceVar(&webhookURLs, "webhook-url", nil, "webhook URLs to POST the run summary to")
+	startCmd.Flags().StringVar(&webhookSecret, "webhook-secret", "", "secret to HMAC-sign the webhook requests, defaults to $WEBHOOK_SECRET")
+	startCmd.Flags().IntVar(&webhookRetries, "webhook-retries", 3, "retries of a failed webhook request")
+	startCmd.Flags().StringSliceVar(&notifyEvents, "notify-on", []string{string(dto.RunStatusSuccess), string(dto.RunStatusPartial), string(dto.RunStatusFailure)}, "run statuses to notify: success, partial, failure")
+	startCmd.Flags().StringVar(&smtpHost, "smtp-host", "", "SMTP host to email the run summary with, no email when empty")
+	startCmd.Flags().IntVar(&smtpPort, "smtp-port", 587, "SMTP port")
+	startCmd.Flags().StringVar(&smtpUsername, "smtp-username", "", "SMTP username")
+	startCmd.Flags().StringVar(&smtpPassword, "smtp-password", "", "SMTP password, defaults to $SMTP_PASSWORD")
+	startCmd.Flags().StringVar(&smtpFrom, "smtp-from", "vcrawler@localhost", "sender of the run summary email")
+	startCmd.Flags().StringSliceVar(&smtpTo, "smtp-to", nil, "recipients of the run summary email")
+	startCmd.Flags().StringVar(&natsURL, "nats-url", "", "NATS server URL to publish an event for each crawled product to, e.g. nats://localhost:4222")
+	startCmd.Flags().StringVar(&busPrefix, "bus-prefix", "vcrawler", "subject prefix of the product events, published to <prefix>.<store>.product")
 }
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"vcrawler/internal/definition"
+	"vcrawler/internal/notify"
+	"vcrawler/internal/stores/adidas"
+	"vcrawler/internal/watch"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	watchArticles       []string
+	watchArticlesFile   string
+	watchInterval       time.Duration
+	watchCooldown       time.Duration
+	watchStateFile      string
+	watchOnce           bool
+	watchStdout         bool
+	watchAlertFile      string
+	watchWebhookURLs    []string
+	watchWebhookSecret  string
+	watchWebhookRetries int
+)
+
+// watchCmd represents the watch command
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Watches articles for restocks and sell-outs.",
+	Long: `Crawls a list of articles on an interval, and alerts when a size
+	comes back in stock online or sells out.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		articles := watchArticles
+		if watchArticlesFile != "" {
+			fileArticles, err := readArticlesFile(watchArticlesFile)
+			if err != nil {
+				slog.Error("Error at reading articles file", "cause", err)
+				return
+			}
+			articles = append(articles, fileArticles...)
+		}
+
+		var alerters []definition.Alerter
+		if watchStdout {
+			alerters = append(alerters, notify.GetWriterAlerter(os.Stdout))
+		}
+		if watchAlertFile != "" {
+			alerters = append(alerters, notify.GetFileAlerter(watchAlertFile))
+		}
+		if len(watchWebhookURLs) > 0 {
+			alerters = append(alerters, notify.GetWebhookAlerter(notify.WebhookOptions{
+				URLs:    watchWebhookURLs,
+				Secret:  cmp.Or(watchWebhookSecret, os.Getenv("WEBHOOK_SECRET")),
+				Retries: watchWebhookRetries,
+			}))
+		}
+
+		watcher := watch.GetWatcher(watch.Options{
+			Articles:  articles,
+			Interval:  watchInterval,
+			Cooldown:  watchCooldown,
+			StateFile: watchStateFile,
+			Once:      watchOnce,
+			Alerters:  alerters,
+		})
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		slog.Info("Starting watcher", "articles", len(articles), "interval", watchInterval)
+		if err := watcher.Watch(ctx, adidas.GetAdidasStore(adidas.Options{})); err != nil {
+			slog.Error("Error at watching articles", "cause", err)
+		}
+	},
+}
+
+// readArticlesFile reads one article code per line, skipping the blank lines and the # comments
+func readArticlesFile(fileName string) ([]string, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var articles []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		articles = append(articles, line)
+	}
+	return articles, scanner.Err()
+}
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+	watchCmd.Flags().StringSliceVarP(&watchArticles, "article", "a", nil, "article codes to watch")
+	watchCmd.Flags().StringVar(&watchArticlesFile, "articles-file", "", "file of article codes to watch, one per line")
+	watchCmd.Flags().DurationVar(&watchInterval, "interval", watch.DefaultInterval, "delay between two checks of the articles")
+	watchCmd.Flags().DurationVar(&watchCooldown, "cooldown", watch.DefaultCooldown, "suppresses a repeated alert of a size within the cooldown")
+	watchCmd.Flags().StringVar(&watchStateFile, "state-file", watch.DefaultStateFile, "file keeping the last seen availability across restarts")
+	watchCmd.Flags().BoolVar(&watchOnce, "once", false, "check the articles a single time, e.g. from cron")
+	watchCmd.Flags().BoolVar(&watchStdout, "stdout", true, "print the alerts to stdout")
+	watchCmd.Flags().StringVar(&watchAlertFile, "alert-file", "", "JSON lines file to append the alerts to")
+	watchCmd.Flags().StringSliceVar(&watchWebhookURLs, "webhook-url", nil, "webhook URLs to POST the alerts to")
+	watchCmd.Flags().StringVar(&watchWebhookSecret, "webhook-secret", "", "secret to HMAC-sign the webhook requests, defaults to $WEBHOOK_SECRET")
+	watchCmd.Flags().IntVar(&watchWebhookRetries, "webhook-retries", 3, "retries of a failed webhook request")
+}
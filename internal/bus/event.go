@@ -0,0 +1,38 @@
+package bus
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"vcrawler/internal/dto"
+)
+
+const (
+	RunIDHeader         = "Vcrawler-Run-Id"
+	StoreHeader         = "Vcrawler-Store"
+	SchemaVersionHeader = "Vcrawler-Schema-Version"
+	// MsgIDHeader deduplicates the redelivered events on JetStream streams
+	MsgIDHeader = "Nats-Msg-Id"
+)
+
+// ProductSubject returns the subject of the product events of a store, e.g. vcrawler.adidas.product
+func ProductSubject(prefix, store string) string {
+	return fmt.Sprintf("%s.%s.product", prefix, store)
+}
+
+// ProductEvent returns the headers and the JSON data of a crawled product event
+func ProductEvent(runID, store string, product dto.Product) (map[string]string, []byte, error) {
+	data, err := json.Marshal(product)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	headers := map[string]string{
+		RunIDHeader:         runID,
+		StoreHeader:         store,
+		SchemaVersionHeader: dto.ProductSchemaVersion,
+		MsgIDHeader:         runID + "." + product.ArticleCode,
+	}
+
+	return headers, data, nil
+}
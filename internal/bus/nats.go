@@ -0,0 +1,47 @@
+package bus
+
+import (
+	"time"
+
+	"vcrawler/internal/definition"
+
+	"github.com/nats-io/nats.go"
+)
+
+type natsBus struct {
+	conn *nats.Conn
+}
+
+// GetNatsBus returns an event bus connected to the NATS server URL, e.g. nats://localhost:4222
+func GetNatsBus(url string, options ...nats.Option) (definition.EventBus, error) {
+	options = append([]nats.Option{
+		nats.Name("vcrawler"),
+		nats.Timeout(10 * time.Second),
+	}, options...)
+
+	conn, err := nats.Connect(url, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &natsBus{conn: conn}, nil
+}
+
+func (b *natsBus) Publish(subject string, headers map[string]string, data []byte) error {
+	msg := nats.NewMsg(subject)
+	for key, value := range headers {
+		msg.Header.Set(key, value)
+	}
+	msg.Data = data
+
+	return b.conn.PublishMsg(msg)
+}
+
+func (b *natsBus) Close() error {
+	if err := b.conn.Flush(); err != nil {
+		return err
+	}
+
+	b.conn.Close()
+	return nil
+}
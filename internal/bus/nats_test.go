@@ -0,0 +1,76 @@
+package bus
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"vcrawler/internal/dto"
+
+	"github.com/nats-io/nats-server/v2/server"
+	natsserver "github.com/nats-io/nats-server/v2/test"
+	"github.com/nats-io/nats.go"
+)
+
+func TestNatsBusPublishesProductEvent(t *testing.T) {
+	opts := natsserver.DefaultTestOptions
+	opts.Port = server.RANDOM_PORT
+	srv := natsserver.RunServer(&opts)
+	defer srv.Shutdown()
+
+	sub, err := nats.Connect(srv.ClientURL())
+	if err != nil {
+		t.Fatalf("connect subscriber: %v", err)
+	}
+	defer sub.Close()
+
+	subject := ProductSubject("vcrawler", "adidas")
+	msgs := make(chan *nats.Msg, 1)
+	if _, err := sub.ChanSubscribe(subject, msgs); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	if err := sub.Flush(); err != nil {
+		t.Fatalf("flush subscriber: %v", err)
+	}
+
+	eventBus, err := GetNatsBus(srv.ClientURL())
+	if err != nil {
+		t.Fatalf("GetNatsBus: %v", err)
+	}
+
+	product := dto.Product{Name: "Tシャツ", ModelCode: "IEH98", ArticleCode: "IS8022"}
+	headers, data, err := ProductEvent("20240901T101500-3f2a9c1e", "adidas", product)
+	if err != nil {
+		t.Fatalf("ProductEvent: %v", err)
+	}
+	if err := eventBus.Publish(subject, headers, data); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if err := eventBus.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	select {
+	case msg := <-msgs:
+		for header, want := range map[string]string{
+			RunIDHeader:         "20240901T101500-3f2a9c1e",
+			StoreHeader:         "adidas",
+			SchemaVersionHeader: dto.ProductSchemaVersion,
+			MsgIDHeader:         "20240901T101500-3f2a9c1e.IS8022",
+		} {
+			if got := msg.Header.Get(header); got != want {
+				t.Errorf("header %s = %q, want %q", header, got, want)
+			}
+		}
+
+		var got dto.Product
+		if err := json.Unmarshal(msg.Data, &got); err != nil {
+			t.Fatalf("unmarshal event: %v", err)
+		}
+		if got.ArticleCode != product.ArticleCode {
+			t.Errorf("article code = %q, want %q", got.ArticleCode, product.ArticleCode)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event received")
+	}
+}
@@ -1,27 +1,69 @@
 package crawler
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"log/slog"
 	"os"
+	"slices"
+	"time"
 
+	"vcrawler/internal/bus"
 	"vcrawler/internal/definition"
 	"vcrawler/internal/dto"
+	"vcrawler/internal/schema"
+)
 
-	"github.com/gocarina/gocsv"
+const (
+	reportFile      = "report.json"
+	failureManifest = "failures.json"
 )
 
+type Options struct {
+	Exporters  []definition.Exporter  // Write the crawled products
+	Uploaders  []definition.Uploader  // Upload the outputs, the failure manifest and the run report
+	Publishers []definition.Publisher // Publish the run report, after the uploads
+	Bus        definition.EventBus    // Receives an event for each product as soon as it is crawled
+	BusPrefix  string                 // Subject prefix of the product events
+	Validate   bool                   // Validates the products against the product JSON Schema before exporting
+	Variants   bool                   // Also crawls the other colorways of the models of the crawled products
+}
+
 type crawler struct {
+	options Options
 }
 
-func GetCrawler() definition.Crawler {
-	return &crawler{}
+func GetCrawler(options Options) definition.Crawler {
+	return &crawler{options: options}
 }
 
-func (c *crawler) Start(store definition.Store) error {
+func (c *crawler) Start(store definition.Store) (err error) {
 	dump := 200
 
-	slog.Info("crawling products listing page")
+	report := &dto.RunReport{
+		RunID:     newRunID(),
+		Store:     store.Name(),
+		StartedAt: time.Now(),
+	}
+	defer func() {
+		c.finish(report, store, err)
+	}()
+
+	if c.options.Bus != nil {
+		subject := bus.ProductSubject(c.options.BusPrefix, report.Store)
+		store.OnProduct(func(product dto.Product) {
+			headers, data, err := bus.ProductEvent(report.RunID, report.Store, product)
+			if err == nil {
+				err = c.options.Bus.Publish(subject, headers, data)
+			}
+			if err != nil {
+				slog.Error("error at publishing product event", "article", product.ArticleCode, "cause", err)
+			}
+		})
+	}
+
+	slog.Info("crawling products listing page", "run_id", report.RunID)
 	productsURL, err := store.GetProductsURL(dump)
 	if err != nil {
 		return err
@@ -33,46 +75,89 @@ func (c *crawler) Start(store definition.Store) error {
 		return err
 	}
 
-	// Convert the products to ProductCsv
-	var productsCsv []dto.ProductCsv
-	for _, product := range products {
-		productsCsv = append(productsCsv, product.ToCsv())
+	if c.options.Variants {
+		variants, err := crawlVariants(store, products)
+		if err != nil {
+			return err
+		}
+		products = append(products, variants...)
 	}
+	report.ProductCount = len(products)
 
-	csvFile, err := os.OpenFile("products.csv", os.O_RDWR|os.O_CREATE, os.ModePerm)
-	if err != nil {
-		return err
+	if c.options.Validate {
+		if err := validate(report, products); err != nil {
+			return err
+		}
 	}
-	defer csvFile.Close()
 
-	err = gocsv.MarshalFile(&productsCsv, csvFile)
-	if err != nil {
-		return err
+	for _, exporter := range c.options.Exporters {
+		if err := exporter.Export(products); err != nil {
+			return err
+		}
+		report.Outputs = append(report.Outputs, exporter.Outputs()...)
 	}
 
-	slog.Info("products data saved to", "file", "products.csv")
+	return nil
+}
+
+// finish writes the failure manifest and the run report, uploads them with the outputs, and publishes the report.
+// The outputs are uploaded before the report is written, so that the report lists their locations.
+func (c *crawler) finish(report *dto.RunReport, store definition.Store, err error) {
+	report.FinishedAt = time.Now()
+	report.Duration = report.FinishedAt.Sub(report.StartedAt).Round(time.Second).String()
+	report.Failures = store.GetFailures()
+	report.FailureCount = len(report.Failures)
 
-	// Convert the products data to JSON format
-	productsJSON, err := json.MarshalIndent(products, "", "  ")
-	if err != nil {
-		return err
+	switch {
+	case err != nil:
+		report.Status = dto.RunStatusFailure
+		report.Error = err.Error()
+	case report.FailureCount > 0 || len(report.Violations) > 0:
+		report.Status = dto.RunStatusPartial
+	default:
+		report.Status = dto.RunStatusSuccess
 	}
 
-	// Write the JSON data to a file
-	fileName := "products.json"
-	file, err := os.Create(fileName)
-	if err != nil {
-		return err
+	failures := report.Failures
+	if failures == nil {
+		failures = []dto.Failure{}
+	}
+	if err := writeJSON(failureManifest, failures); err != nil {
+		slog.Error("error at writing failure manifest", "cause", err)
+	} else {
+		report.FailureManifest = failureManifest
 	}
-	defer file.Close()
 
-	_, err = file.Write(productsJSON)
-	if err != nil {
-		return err
+	files := append([]string{}, report.Outputs...)
+	if report.FailureManifest != "" {
+		files = append(files, report.FailureManifest)
 	}
+	c.upload(report, files)
 
-	slog.Info("products data saved to", "file", fileName)
-	return nil
+	report.ReportFile = reportFile
+	if err := writeJSON(reportFile, report); err != nil {
+		slog.Error("error at writing run report", "cause", err)
+		report.ReportFile = ""
+	} else {
+		c.upload(report, []string{report.ReportFile})
+	}
+
+	slog.Info("crawl run finished", "run_id", report.RunID, "status", report.Status, "products", report.ProductCount, "failures", report.FailureCount, "duration", report.Duration)
+
+	for _, publisher := range c.options.Publishers {
+		if err := publisher.Publish(report); err != nil {
+			slog.Error("error at publishing run", "run_id", report.RunID, "cause", err)
+		}
+	}
+}
+
+// upload uploads the files of the run with every uploader
+func (c *crawler) upload(report *dto.RunReport, files []string) {
+	for _, uploader := range c.options.Uploaders {
+		if err := uploader.Upload(report, files); err != nil {
+			slog.Error("error at uploading run files", "run_id", report.RunID, "cause", err)
+		}
+	}
 }
 
 func (c *crawler) Test(dump int, store definition.Store) error {
@@ -100,3 +185,77 @@ func (c *crawler) Test(dump int, store definition.Store) error {
 
 	return nil
 }
+
+// crawlVariants crawls the colorways of the models of the products that weren't crawled yet
+func crawlVariants(store definition.Store, products []dto.Product) ([]dto.Product, error) {
+	var (
+		modelCodes []string
+		crawled    = map[string]bool{}
+	)
+	for _, product := range products {
+		if !slices.Contains(modelCodes, product.ModelCode) {
+			modelCodes = append(modelCodes, product.ModelCode)
+		}
+		crawled[product.ArticleCode] = true
+	}
+
+	slog.Info("searching colorways of the models", "models", len(modelCodes))
+	modelArticles, err := store.GetModelArticles(modelCodes)
+	if err != nil {
+		return nil, err
+	}
+
+	var articleCodes []string
+	for _, modelCode := range modelCodes {
+		for _, articleCode := range modelArticles[modelCode] {
+			if !crawled[articleCode] {
+				crawled[articleCode] = true
+				articleCodes = append(articleCodes, articleCode)
+			}
+		}
+	}
+	if len(articleCodes) == 0 {
+		return nil, nil
+	}
+
+	slog.Info("crawling colorways", "articles", len(articleCodes))
+	return store.GetProductsDetail(store.GetArticlesURL(articleCodes))
+}
+
+// validate adds the violations of the products to the report
+func validate(report *dto.RunReport, products []dto.Product) error {
+	validator, err := schema.GetValidator()
+	if err != nil {
+		return err
+	}
+
+	for _, product := range products {
+		violations, err := validator.Validate(product)
+		if err != nil {
+			return err
+		}
+
+		for _, v := range violations {
+			slog.Warn("product schema violation", "article", v.ArticleCode, "location", v.Location, "message", v.Message)
+		}
+		report.Violations = append(report.Violations, violations...)
+	}
+
+	slog.Info("products validated", "schema", schema.ProductID, "products", len(products), "violations", len(report.Violations))
+	return nil
+}
+
+// newRunID returns a sortable unique id of a crawl run, e.g. 20240901T101500-3f2a9c1e
+func newRunID() string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return time.Now().Format("20060102T150405") + "-" + hex.EncodeToString(b)
+}
+
+func writeJSON(fileName string, v any) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(fileName, b, 0644)
+}
@@ -1,23 +1,72 @@
 package definition
 
 import (
+	"context"
+
 	"vcrawler/internal/dto"
 )
 
 type Downloader interface {
-	// DownloadImage(url string) (imageData []byte, err error)
+	// DownloadImage returns the data of the image at the URL
+	DownloadImage(url string) (imageData []byte, err error)
 }
 
 type Store interface {
+	// Name returns the name of the store
+	Name() string
 	// GetProductsURL returns a list of product URLs from the listing page
 	GetProductsURL(dumpLimit int) ([]string, error)
+	// GetModelArticles returns the article codes of all the colorways of each model code
+	GetModelArticles(modelCodes []string) (map[string][]string, error)
+	// GetArticlesURL returns the product URLs of the article codes
+	GetArticlesURL(articleCodes []string) []string
 	// GetProductDetail returns the product details from the product page
 	GetProductsDetail(productsURL []string) ([]dto.Product, error)
+	// GetFailures returns the pages that failed to crawl
+	GetFailures() []dto.Failure
+	// ResetFailures clears the failures, e.g. between the checks of a long-running watch
+	ResetFailures()
+	// OnProduct registers a callback called with each product as soon as it is crawled
+	OnProduct(callback func(product dto.Product))
 
 	// Downloader implements the downloader for the store
 	Downloader
 }
 
+type Exporter interface {
+	// Export writes the crawled products to the exporter destination
+	Export(products []dto.Product) error
+	// Outputs returns the files and locations written by the exporter
+	Outputs() []string
+}
+
+type Publisher interface {
+	// Publish publishes the report and the files of a finished crawl run
+	Publish(report *dto.RunReport) error
+}
+
+type Uploader interface {
+	// Upload uploads the files of a crawl run and adds their locations to the report uploads
+	Upload(report *dto.RunReport, files []string) error
+}
+
+type EventBus interface {
+	// Publish publishes a message with its headers to the subject
+	Publish(subject string, headers map[string]string, data []byte) error
+	// Close flushes the pending messages and closes the connection
+	Close() error
+}
+
+type Alerter interface {
+	// Alert sends a stock alert of a watched article
+	Alert(alert dto.StockAlert) error
+}
+
+type Watcher interface {
+	// Watch crawls the watched articles of the store on an interval until the context is done
+	Watch(ctx context.Context, store Store) error
+}
+
 type Crawler interface {
 	Start(store Store) error
 	Test(dumpLimit int, store Store) error
@@ -0,0 +1,163 @@
+package diff
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+
+	"vcrawler/internal/dto"
+)
+
+type ChangeKind string
+
+const (
+	ChangeAdded   ChangeKind = "added"
+	ChangeRemoved ChangeKind = "removed"
+	ChangePrice   ChangeKind = "price"
+	ChangeStock   ChangeKind = "stock"
+	ChangeReview  ChangeKind = "review"
+	ChangeRating  ChangeKind = "rating"
+	ChangeImages  ChangeKind = "images"
+)
+
+// Change is a single difference of a product between two snapshots
+type Change struct {
+	ArticleCode string     `json:"article_code"`
+	Name        string     `json:"name"`
+	Kind        ChangeKind `json:"kind"`
+	Field       string     `json:"field,omitempty"` // e.g. price.with_tax or skus[M].is_sold_out
+	Old         string     `json:"old,omitempty"`
+	New         string     `json:"new,omitempty"`
+}
+
+// LoadSnapshot reads the products of a products.json snapshot
+func LoadSnapshot(fileName string) ([]dto.Product, error) {
+	b, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	var products []dto.Product
+	if err := json.Unmarshal(b, &products); err != nil {
+		return nil, fmt.Errorf("error at unmarshalling %s: %w", fileName, err)
+	}
+	return products, nil
+}
+
+// Compare returns the changes from the old to the new snapshot, matching the products by article code
+func Compare(oldProducts, newProducts []dto.Product) []Change {
+	olds := byArticleCode(oldProducts)
+	news := byArticleCode(newProducts)
+
+	var changes []Change
+	for code, n := range news {
+		o, ok := olds[code]
+		if !ok {
+			changes = append(changes, Change{ArticleCode: code, Name: n.Name, Kind: ChangeAdded})
+			continue
+		}
+		changes = append(changes, compareProduct(o, n)...)
+	}
+
+	for code, o := range olds {
+		if _, ok := news[code]; !ok {
+			changes = append(changes, Change{ArticleCode: code, Name: o.Name, Kind: ChangeRemoved})
+		}
+	}
+
+	slices.SortStableFunc(changes, func(a, b Change) int {
+		return cmp.Or(
+			cmp.Compare(a.ArticleCode, b.ArticleCode),
+			cmp.Compare(kindOrder(a.Kind), kindOrder(b.Kind)),
+			cmp.Compare(a.Field, b.Field),
+		)
+	})
+	return changes
+}
+
+func compareProduct(o, n dto.Product) []Change {
+	var changes []Change
+	add := func(kind ChangeKind, field, oldValue, newValue string) {
+		if oldValue != newValue {
+			changes = append(changes, Change{ArticleCode: n.ArticleCode, Name: n.Name, Kind: kind, Field: field, Old: oldValue, New: newValue})
+		}
+	}
+
+	add(ChangePrice, "price.with_tax", strconv.Itoa(o.Price.WithTax), strconv.Itoa(n.Price.WithTax))
+	add(ChangePrice, "price.without_tax", strconv.Itoa(o.Price.WithoutTax), strconv.Itoa(n.Price.WithoutTax))
+	add(ChangePrice, "price.discount_type", o.Price.DiscountType, n.Price.DiscountType)
+
+	oldSkus := map[string]dto.Sku{}
+	for _, sku := range o.Skus {
+		oldSkus[sku.SizeName] = sku
+	}
+	for _, newSku := range n.Skus {
+		oldSku, ok := oldSkus[newSku.SizeName]
+		if !ok {
+			add(ChangeStock, fmt.Sprintf("skus[%s]", newSku.SizeName), "", "added")
+			continue
+		}
+		delete(oldSkus, newSku.SizeName)
+
+		add(ChangeStock, fmt.Sprintf("skus[%s].is_stock", newSku.SizeName), strconv.FormatBool(oldSku.Status.IsStockEc), strconv.FormatBool(newSku.Status.IsStockEc))
+		add(ChangeStock, fmt.Sprintf("skus[%s].is_stock_store", newSku.SizeName), strconv.FormatBool(oldSku.Status.IsStockStore), strconv.FormatBool(newSku.Status.IsStockStore))
+		add(ChangeStock, fmt.Sprintf("skus[%s].is_sold_out", newSku.SizeName), strconv.FormatBool(oldSku.Status.IsSoldOut), strconv.FormatBool(newSku.Status.IsSoldOut))
+		add(ChangeStock, fmt.Sprintf("skus[%s].stock_message", newSku.SizeName), oldSku.StockMessage, newSku.StockMessage)
+	}
+	for size := range oldSkus {
+		add(ChangeStock, fmt.Sprintf("skus[%s]", size), "removed", "")
+	}
+
+	oldReviews := map[string]bool{}
+	for _, review := range o.Reviews {
+		oldReviews[reviewKey(review)] = true
+	}
+	for _, review := range n.Reviews {
+		if !oldReviews[reviewKey(review)] {
+			add(ChangeReview, "reviews", "", fmt.Sprintf("%s (%s) [%s/%s]: %s", review.AuthorName, review.DatePublished, review.RatingValue, review.BestRating, review.Body))
+		}
+	}
+
+	add(ChangeRating, "review_count", o.ReviewCount, n.ReviewCount)
+	add(ChangeRating, "rating", o.Rating, n.Rating)
+	add(ChangeRating, "recommended_rate", o.RecommendedRate, n.RecommendedRate)
+
+	oldImages := map[string]bool{}
+	for _, image := range o.Images {
+		oldImages[image] = true
+	}
+	for _, image := range n.Images {
+		if !oldImages[image] {
+			add(ChangeImages, "images", "", image)
+		}
+		delete(oldImages, image)
+	}
+	for _, image := range o.Images {
+		if oldImages[image] {
+			add(ChangeImages, "images", image, "")
+		}
+	}
+
+	return changes
+}
+
+func byArticleCode(products []dto.Product) map[string]dto.Product {
+	result := make(map[string]dto.Product, len(products))
+	for _, product := range products {
+		result[product.ArticleCode] = product
+	}
+	return result
+}
+
+func reviewKey(review dto.Review) string {
+	return review.AuthorName + "\x00" + review.DatePublished + "\x00" + review.Body
+}
+
+var kinds = []ChangeKind{ChangeAdded, ChangeRemoved, ChangePrice, ChangeStock, ChangeReview, ChangeRating, ChangeImages}
+
+func kindOrder(kind ChangeKind) int {
+	return slices.Index(kinds, kind)
+}
@@ -0,0 +1,187 @@
+package diff
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"vcrawler/internal/dto"
+)
+
+func product(code string, edit func(p *dto.Product)) dto.Product {
+	p := dto.Product{
+		ArticleCode: code,
+		Name:        "Product " + code,
+		Price:       dto.Price{WithTax: 19800, WithoutTax: 18000},
+		Skus: []dto.Sku{
+			{SizeName: "S", Status: dto.SkuStatus{IsStockEc: true}},
+			{SizeName: "M", Status: dto.SkuStatus{IsStockEc: true}},
+		},
+		Reviews:         []dto.Review{{AuthorName: "taro", DatePublished: "2024-08-01", Body: "良い", RatingValue: "5", BestRating: "5"}},
+		ReviewCount:     "1",
+		Rating:          "5",
+		RecommendedRate: "100%",
+		Images:          []string{"https://example.com/1.jpg", "https://example.com/2.jpg"},
+	}
+	if edit != nil {
+		edit(&p)
+	}
+	return p
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name string
+		old  []dto.Product
+		new  []dto.Product
+		want []Change
+	}{
+		{
+			name: "unchanged",
+			old:  []dto.Product{product("A", nil)},
+			new:  []dto.Product{product("A", nil)},
+		},
+		{
+			name: "matched by article code, not by position",
+			old:  []dto.Product{product("A", nil), product("B", nil)},
+			new:  []dto.Product{product("B", nil), product("A", nil)},
+		},
+		{
+			name: "added and removed",
+			old:  []dto.Product{product("A", nil)},
+			new:  []dto.Product{product("B", nil)},
+			want: []Change{
+				{ArticleCode: "A", Name: "Product A", Kind: ChangeRemoved},
+				{ArticleCode: "B", Name: "Product B", Kind: ChangeAdded},
+			},
+		},
+		{
+			name: "price and discount type",
+			old:  []dto.Product{product("A", nil)},
+			new: []dto.Product{product("A", func(p *dto.Product) {
+				p.Price = dto.Price{WithTax: 13860, WithoutTax: 12600, DiscountType: "sale"}
+			})},
+			want: []Change{
+				{ArticleCode: "A", Name: "Product A", Kind: ChangePrice, Field: "price.discount_type", New: "sale"},
+				{ArticleCode: "A", Name: "Product A", Kind: ChangePrice, Field: "price.with_tax", Old: "19800", New: "13860"},
+				{ArticleCode: "A", Name: "Product A", Kind: ChangePrice, Field: "price.without_tax", Old: "18000", New: "12600"},
+			},
+		},
+		{
+			name: "sku stock flips, added and removed sizes",
+			old:  []dto.Product{product("A", nil)},
+			new: []dto.Product{product("A", func(p *dto.Product) {
+				p.Skus = []dto.Sku{
+					{SizeName: "M", Status: dto.SkuStatus{IsSoldOut: true}, StockMessage: "在庫なし"},
+					{SizeName: "L", Status: dto.SkuStatus{IsStockEc: true}},
+				}
+			})},
+			want: []Change{
+				{ArticleCode: "A", Name: "Product A", Kind: ChangeStock, Field: "skus[L]", New: "added"},
+				{ArticleCode: "A", Name: "Product A", Kind: ChangeStock, Field: "skus[M].is_sold_out", Old: "false", New: "true"},
+				{ArticleCode: "A", Name: "Product A", Kind: ChangeStock, Field: "skus[M].is_stock", Old: "true", New: "false"},
+				{ArticleCode: "A", Name: "Product A", Kind: ChangeStock, Field: "skus[M].stock_message", New: "在庫なし"},
+				{ArticleCode: "A", Name: "Product A", Kind: ChangeStock, Field: "skus[S]", Old: "removed"},
+			},
+		},
+		{
+			name: "new review and rating",
+			old:  []dto.Product{product("A", nil)},
+			new: []dto.Product{product("A", func(p *dto.Product) {
+				p.Reviews = append(p.Reviews, dto.Review{AuthorName: "hanako", DatePublished: "2024-08-20", Body: "小さめ", RatingValue: "3", BestRating: "5"})
+				p.ReviewCount, p.Rating, p.RecommendedRate = "2", "4", "50%"
+			})},
+			want: []Change{
+				{ArticleCode: "A", Name: "Product A", Kind: ChangeReview, Field: "reviews", New: "hanako (2024-08-20) [3/5]: 小さめ"},
+				{ArticleCode: "A", Name: "Product A", Kind: ChangeRating, Field: "rating", Old: "5", New: "4"},
+				{ArticleCode: "A", Name: "Product A", Kind: ChangeRating, Field: "recommended_rate", Old: "100%", New: "50%"},
+				{ArticleCode: "A", Name: "Product A", Kind: ChangeRating, Field: "review_count", Old: "1", New: "2"},
+			},
+		},
+		{
+			name: "image set, not image order",
+			old:  []dto.Product{product("A", nil)},
+			new: []dto.Product{product("A", func(p *dto.Product) {
+				p.Images = []string{"https://example.com/3.jpg", "https://example.com/1.jpg"}
+			})},
+			want: []Change{
+				{ArticleCode: "A", Name: "Product A", Kind: ChangeImages, Field: "images", New: "https://example.com/3.jpg"},
+				{ArticleCode: "A", Name: "Product A", Kind: ChangeImages, Field: "images", Old: "https://example.com/2.jpg"},
+			},
+		},
+		{
+			name: "reordered images",
+			old:  []dto.Product{product("A", nil)},
+			new: []dto.Product{product("A", func(p *dto.Product) {
+				slices.Reverse(p.Images)
+			})},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Compare(tt.old, tt.new); !slices.Equal(got, tt.want) {
+				t.Errorf("Compare() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	oldProducts := []dto.Product{product("A", nil), product("B", nil)}
+	newProducts := []dto.Product{
+		product("A", func(p *dto.Product) {
+			p.Price.WithTax = 13860
+			p.Skus[0].Status.IsSoldOut = true
+		}),
+		product("C", func(p *dto.Product) { p.Name = "Product, \"C\"" }),
+	}
+
+	var b bytes.Buffer
+	if err := Write(&b, OutputCSV, Compare(oldProducts, newProducts)); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	golden := filepath.Join("testdata", "changes.csv")
+	if os.Getenv("UPDATE_GOLDEN") != "" {
+		if err := os.WriteFile(golden, b.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := b.String(); got != string(want) {
+		t.Errorf("csv output =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWriteTextAndJSON(t *testing.T) {
+	changes := Compare([]dto.Product{product("A", nil)}, []dto.Product{product("A", func(p *dto.Product) { p.Rating = "4" })})
+
+	var b bytes.Buffer
+	if err := Write(&b, OutputText, changes); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	for _, want := range []string{"A Product A\n", "  rating   rating: 5 -> 4\n", "1 changes: added 0 removed 0 price 0 stock 0 review 0 rating 1 images 0\n"} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("text output =\n%s\nwant it to contain %q", b.String(), want)
+		}
+	}
+
+	b.Reset()
+	if err := Write(&b, OutputJSON, nil); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if got := b.String(); got != "[]\n" {
+		t.Errorf("json output without changes = %q, want []", got)
+	}
+
+	if err := Write(&b, "xml", changes); err == nil {
+		t.Error("Write() with an unknown output error = nil")
+	}
+}
@@ -0,0 +1,95 @@
+package diff
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+	OutputCSV  = "csv"
+)
+
+// Write writes the changes in the output format: text, json or csv
+func Write(w io.Writer, output string, changes []Change) error {
+	switch output {
+	case OutputText:
+		return writeText(w, changes)
+	case OutputJSON:
+		return writeJSON(w, changes)
+	case OutputCSV:
+		return writeCSV(w, changes)
+	}
+	return fmt.Errorf("unknown diff output: %q", output)
+}
+
+func writeText(w io.Writer, changes []Change) error {
+	counts := map[ChangeKind]int{}
+	var article string
+
+	for _, c := range changes {
+		counts[c.Kind]++
+
+		if c.ArticleCode != article {
+			article = c.ArticleCode
+			if _, err := fmt.Fprintf(w, "\n%s %s\n", c.ArticleCode, c.Name); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		switch {
+		case c.Field == "":
+			_, err = fmt.Fprintf(w, "  %s\n", c.Kind)
+		case c.Old == "":
+			_, err = fmt.Fprintf(w, "  %-8s %s: + %s\n", c.Kind, c.Field, c.New)
+		case c.New == "":
+			_, err = fmt.Fprintf(w, "  %-8s %s: - %s\n", c.Kind, c.Field, c.Old)
+		default:
+			_, err = fmt.Fprintf(w, "  %-8s %s: %s -> %s\n", c.Kind, c.Field, c.Old, c.New)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if _, err := fmt.Fprintf(w, "\n%d changes:", len(changes)); err != nil {
+		return err
+	}
+	for _, kind := range kinds {
+		if _, err := fmt.Fprintf(w, " %s %d", kind, counts[kind]); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
+
+func writeJSON(w io.Writer, changes []Change) error {
+	if changes == nil {
+		changes = []Change{}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(changes)
+}
+
+func writeCSV(w io.Writer, changes []Change) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"article_code", "name", "kind", "field", "old", "new"}); err != nil {
+		return err
+	}
+
+	for _, c := range changes {
+		if err := cw.Write([]string{c.ArticleCode, c.Name, string(c.Kind), c.Field, c.Old, c.New}); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,25 @@
+package dto
+
+import "time"
+
+type StockAlertKind string
+
+const (
+	// StockAlertRestock is a size that came back in stock online
+	StockAlertRestock StockAlertKind = "restock"
+	// StockAlertSoldOut is a size that isn't in stock online anymore
+	StockAlertSoldOut StockAlertKind = "sold_out"
+)
+
+// StockAlert is a change of the online availability of a watched size
+type StockAlert struct {
+	Kind         StockAlertKind `json:"kind"`
+	Store        string         `json:"store"`
+	ArticleCode  string         `json:"article_code"`
+	Name         string         `json:"name"`
+	URL          string         `json:"url"`
+	SizeName     string         `json:"size_name"`
+	Status       SkuStatus      `json:"status"`
+	StockMessage string         `json:"stock_message,omitempty"` // e.g. a low stock warning
+	At           time.Time      `json:"at"`
+}
@@ -0,0 +1,44 @@
+package dto
+
+// Model is a product model with all its colorways
+type Model struct {
+	ModelCode string    `json:"model_code"`
+	Name      string    `json:"name"`
+	Variants  []Variant `json:"variants"`
+}
+
+// Variant is a colorway of a model, one article
+type Variant struct {
+	ArticleCode string   `json:"article_code"`
+	ColorName   string   `json:"color_name"`
+	URL         string   `json:"url"`
+	Images      []string `json:"images"`
+	Price       Price    `json:"price"`
+	Skus        []Sku    `json:"skus"`
+}
+
+// GroupByModel groups the products by model code, in the order of their first product
+func GroupByModel(products []Product) []Model {
+	var (
+		models []Model
+		index  = map[string]int{}
+	)
+	for _, product := range products {
+		i, ok := index[product.ModelCode]
+		if !ok {
+			i = len(models)
+			index[product.ModelCode] = i
+			models = append(models, Model{ModelCode: product.ModelCode, Name: product.Name})
+		}
+
+		models[i].Variants = append(models[i].Variants, Variant{
+			ArticleCode: product.ArticleCode,
+			ColorName:   product.ColorName,
+			URL:         product.URL,
+			Images:      product.Images,
+			Price:       product.Price,
+			Skus:        product.Skus,
+		})
+	}
+	return models
+}
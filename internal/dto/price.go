@@ -0,0 +1,102 @@
+package dto
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+)
+
+type Price struct {
+	WithTax           int     `json:"with_tax"`            // Amount with tax, in yen for JPY
+	WithoutTax        int     `json:"without_tax"`         // Amount before tax
+	Currency          string  `json:"currency"`            // ISO 4217 code, e.g. JPY
+	TaxRate           float64 `json:"tax_rate"`            // e.g. 0.1 for the 10% consumption tax
+	TaxIncluded       bool    `json:"tax_included"`        // The displayed price includes the tax
+	DisplayWithTax    string  `json:"display_with_tax"`    // As displayed by the store, e.g. 19,800
+	DisplayWithoutTax string  `json:"display_without_tax"` // e.g. 18,000
+	DiscountType      string  `json:"discount_type"`
+}
+
+// FormatYen formats an amount with thousands separators, e.g. 19,800
+func FormatYen(amount int) string {
+	s := strconv.Itoa(amount)
+	sign := ""
+	if amount < 0 {
+		sign, s = "-", s[1:]
+	}
+
+	var b strings.Builder
+	for i, r := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(r)
+	}
+	return sign + b.String()
+}
+
+// ParseYen returns the amount of a displayed price like "19,800", "¥19,800" or "18000.000000"
+func ParseYen(display string) (int, bool) {
+	display = strings.NewReplacer(",", "", "¥", "", "￥", "", "円", "").Replace(strings.TrimSpace(display))
+	amount, err := strconv.ParseFloat(display, 64)
+	if err != nil {
+		return 0, false
+	}
+	return int(math.Round(amount)), true
+}
+
+// UnmarshalJSON reads the prices of the products.json written before schema version 2,
+// whose amounts were the display strings, e.g. {"with_tax": "19,800", "without_tax": "18000.000000"}
+func (p *Price) UnmarshalJSON(b []byte) error {
+	type price Price
+	var v struct {
+		price
+		WithTax    json.RawMessage `json:"with_tax"`
+		WithoutTax json.RawMessage `json:"without_tax"`
+	}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	*p = Price(v.price)
+
+	var err error
+	if p.WithTax, err = unmarshalAmount(v.WithTax, &p.DisplayWithTax); err != nil {
+		return err
+	}
+	if p.WithoutTax, err = unmarshalAmount(v.WithoutTax, &p.DisplayWithoutTax); err != nil {
+		return err
+	}
+
+	if p.Currency == "" && p.WithTax > 0 {
+		// Legacy prices are all tax inclusive yen prices
+		p.Currency, p.TaxIncluded = "JPY", true
+		if p.WithoutTax > 0 {
+			p.TaxRate = math.Round((float64(p.WithTax)/float64(p.WithoutTax)-1)*100) / 100
+		}
+	}
+	return nil
+}
+
+// unmarshalAmount returns a numeric amount, or the amount of a legacy display string, which also becomes the display
+func unmarshalAmount(raw json.RawMessage, display *string) (int, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return 0, nil
+	}
+
+	var amount int
+	if err := json.Unmarshal(raw, &amount); err == nil {
+		return amount, nil
+	}
+
+	var legacy string
+	if err := json.Unmarshal(raw, &legacy); err != nil {
+		return 0, err
+	}
+
+	amount, _ = ParseYen(legacy)
+	if *display == "" {
+		*display = FormatYen(amount)
+	}
+	return amount, nil
+}
@@ -1,18 +1,13 @@
 package dto
 
-import "strings"
+// ProductSchemaVersion is the version of the Product schema, bumped on breaking changes
+const ProductSchemaVersion = "2"
 
 type SizeChoice struct {
 	AvailableSize  string `csv:"available_size" json:"available_size"`
 	SenseOfTheSize string `csv:"sense_of_the_size" json:"sense_of_the_size"`
 }
 
-type Price struct {
-	WithTax      string `csv:"with_tax" json:"with_tax"`
-	WithoutTax   string `csv:"without_tax" json:"without_tax"`
-	DiscountType string `csv:"discount_type" json:"discount_type"`
-}
-
 type Category struct {
 	Label string `csv:"label" json:"label"`
 	Link  string `csv:"link" json:"link"`
@@ -30,6 +25,17 @@ type Coordinate struct {
 	ProductPrice Price  `csv:"product_price" json:"product_price"`
 }
 
+// Image is a product image with all its sizes
+type Image struct {
+	Large   string `json:"large" jsonschema:"format=uri"`
+	Medium  string `json:"medium"`
+	Small   string `json:"small"`
+	Caption string `json:"caption"`
+	View    string `json:"view"`              // View type parsed from the file name, e.g. on_model-standard_view
+	Order   int    `json:"order"`             // Display order on the product page, from 1
+	Listing string `json:"listing,omitempty"` // 1, 2 or hover when the listing page shows the image
+}
+
 type Description struct {
 	Title   string   `csv:"title" json:"title"`
 	General string   `csv:"general" json:"general"`
@@ -42,20 +48,58 @@ type SkuStatus struct {
 	IsSoldOut    bool `csv:"is_sold_out" json:"is_sold_out"`
 }
 
+// SizeSystem is the sizing system of a canonical size
+type SizeSystem string
+
+const (
+	// SizeSystemApparelLetter is the letter sizes of the apparel, e.g. XS, M, 2XL
+	SizeSystemApparelLetter SizeSystem = "apparel_letter"
+	// SizeSystemJPShoe is the JP shoe sizes, the foot length in cm, e.g. 26.5
+	SizeSystemJPShoe SizeSystem = "jp_shoe_cm"
+	// SizeSystemKids is the kids sizes, the height in cm, e.g. 120
+	SizeSystemKids SizeSystem = "kids"
+	// SizeSystemNumeric is the other numeric sizes, e.g. the waist of the pants
+	SizeSystemNumeric SizeSystem = "numeric"
+	// SizeSystemOneSize is the single size of an article, e.g. FREE
+	SizeSystemOneSize SizeSystem = "one_size"
+)
+
+// Size is the canonical size of a size label, to compare the sizes across products
+type Size struct {
+	Code    string     `json:"code"`                                                                      // e.g. 2XL for XXL and J/2XL
+	System  SizeSystem `json:"system" jsonschema:"enum=apparel_letter|jp_shoe_cm|kids|numeric|one_size|"` // Empty when the label isn't a known size
+	Variant string     `json:"variant,omitempty"`                                                         // e.g. tall for 2XLT, 7inch丈 for S-7inch丈
+}
+
 type Sku struct {
-	SizeName string    `csv:"size_name" json:"size_name"`
-	Code     string    `csv:"code" json:"code"`
-	Status   SkuStatus `csv:"status" json:"status"`
+	SizeName       string    `csv:"size_name" json:"size_name"`
+	CanonicalSize  Size      `json:"canonical_size"`
+	Code           string    `csv:"code" json:"code"`
+	Status         SkuStatus `csv:"status" json:"status"`
+	StockMessage   string    `json:"stock_message"`     // e.g. a low stock warning, empty when there is none
+	StockIcon      string    `json:"stock_icon"`        // Icon of the stock message
+	CanAddToCart   bool      `json:"can_add_to_cart"`   // The add to cart button is enabled
+	AddToCartLabel string    `json:"add_to_cart_label"` // Label of the add to cart button
 }
 
 type Measurement struct {
-	Type  string `json:"type"`
-	Value string `json:"value"`
+	Type  string  `json:"type"`
+	Value string  `json:"value"`
+	Key   string  `json:"key"`                                                                          // Canonical key of the type, e.g. chest for 胸囲, empty when unknown
+	Label string  `json:"label"`                                                                        // English label of the type, e.g. Chest
+	Min   float64 `json:"min"`                                                                          // Lower bound of the value, equal to Max for single values, 0 when Raw is set
+	Max   float64 `json:"max"`                                                                          // Upper bound of the value, 0 when Raw is set
+	Unit  string  `json:"unit,omitempty" jsonschema:"enum=cm|mm|inch,description=Missing when unknown"` // cm, mm or inch of the value or else of its chart, empty when unknown
+	Raw   string  `json:"raw,omitempty"`                                                                // Value that couldn't be parsed into Min and Max
 }
 
 type SizeChart struct {
-	Size         string        `csv:"size" json:"size"`
-	Measurements []Measurement `json:"measurements"`
+	Section       string        `json:"section"` // Index of the chart section, e.g. "0" for the first table
+	Size          string        `csv:"size" json:"size"`
+	CanonicalSize Size          `json:"canonical_size"`
+	ActualSize    bool          `json:"actual_size"` // The measurements are of the garment (実寸), not of the body it fits (ヌード寸法)
+	Exact         bool          `json:"exact"`       // The chart is the chart of the model, not the reference chart of its category
+	Measurements  []Measurement `json:"measurements"`
 }
 
 type Technology struct {
@@ -64,25 +108,73 @@ type Technology struct {
 }
 
 type Review struct {
-	AuthorName    string `csv:"author_name" json:"author_name"`
-	DatePublished string `csv:"date_published" json:"date_published"`
-	Body          string `csv:"body" json:"body"`
-	BestRating    string `csv:"best_rating" json:"best_rating"`
-	RatingValue   string `csv:"rating_value" json:"rating_value"`
+	AuthorName    string            `csv:"author_name" json:"author_name"`
+	DatePublished string            `csv:"date_published" json:"date_published"`
+	Body          string            `csv:"body" json:"body"`
+	BestRating    string            `csv:"best_rating" json:"best_rating"`
+	RatingValue   string            `csv:"rating_value" json:"rating_value"`
+	Title         string            `json:"title"`
+	Attributes    []ReviewAttribute `json:"attributes"`  // Reviewer attributes, e.g. size purchased, usual size and fit
+	HelpfulYes    int               `json:"helpful_yes"` // Helpfulness votes
+	HelpfulNo     int               `json:"helpful_no"`
+	Photos        []string          `json:"photos"`
 }
 
+type ReviewAttribute struct {
+	Label string `json:"label"`
+	Value string `json:"value"`
+}
+
+// RatingMissingReason tells why the rating of a product is missing
+type RatingMissingReason string
+
+const (
+	// RatingMissingNoReviews is a product without reviews, its rating isn't fetched
+	RatingMissingNoReviews RatingMissingReason = "no_reviews"
+	// RatingMissingFetchFailed is a rating request that failed
+	RatingMissingFetchFailed RatingMissingReason = "fetch_failed"
+	// RatingMissingMalformedPayload is a rating response that couldn't be parsed
+	RatingMissingMalformedPayload RatingMissingReason = "malformed_payload"
+	// RatingMissingNoSummary is a rating response without rating summary
+	RatingMissingNoSummary RatingMissingReason = "no_summary"
+	// RatingMissingNoRating is a rating summary without average rating
+	RatingMissingNoRating RatingMissingReason = "no_rating"
+)
+
 type RatingSense struct {
-	Type  string `csv:"type" json:"type"`
-	Value string `csv:"value" json:"value"`
+	Type     string  `csv:"type" json:"type"`
+	Value    string  `csv:"value" json:"value"`
+	Position float64 `json:"position" jsonschema:"minimum=0,maximum=5"` // Average position on the 1 to 5 slider, 0 when unknown
+	MinLabel string  `json:"min_label"`                                 // Label of the slider at 1, e.g. 小さい
+	MaxLabel string  `json:"max_label"`                                 // Label of the slider at 5, e.g. 大きい
+}
+
+// RatingCount is a bar of the star rating histogram
+type RatingCount struct {
+	Stars int `json:"stars" jsonschema:"minimum=1,maximum=5"`
+	Count int `json:"count" jsonschema:"minimum=0"`
 }
 
 type Product struct {
-	Name            string        `csv:"name" json:"name"`
-	ModelCode       string        `csv:"model_code" json:"model_code"`
-	ArticleCode     string        `csv:"article_code" json:"article_code"`
+	Name        string `csv:"name" json:"name" jsonschema:"minLength=1"`
+	ModelCode   string `csv:"model_code" json:"model_code" jsonschema:"pattern=^[A-Z0-9]+$"`
+	ArticleCode string `csv:"article_code" json:"article_code" jsonschema:"pattern=^[A-Z0-9]+$"`
+	// From ColorName to DiscountPrice, the article metadata of the listing page, empty when the article wasn't listed
+	ColorName       string        `json:"color_name"`
+	BrandName       string        `json:"brand_name"`
+	SportName       string        `json:"sport_name"`
+	GenderName      string        `json:"gender_name"`
+	GenreName       string        `json:"genre_name"`
+	ReleaseDate     string        `json:"release_date"`
+	ItemStatus      string        `json:"item_status"`
+	Limited         bool          `json:"limited"`
+	OptionalLabel   string        `json:"optional_label"`
+	FixedPrice      int           `json:"fixed_price"`    // Regular price with tax
+	DiscountPrice   int           `json:"discount_price"` // Discounted price with tax
 	Price           Price         `csv:"price" json:"price"`
-	URL             string        `csv:"url" json:"url"`
+	URL             string        `csv:"url" json:"url" jsonschema:"format=uri"`
 	Images          []string      `csv:"images" json:"images"`
+	ImageDetails    []Image       `json:"image_details"`
 	Breadcrumb      string        `json:"breadcrumb"`
 	Breadcrumbs     []Breadcrumb  `json:"breadcrumbs"`
 	KWs             string        `csv:"kws" json:"kws"`
@@ -98,79 +190,10 @@ type Product struct {
 	Rating          string        `json:"rating"`
 	RecommendedRate string        `json:"recommended_rate"`
 	RatingSenses    []RatingSense `json:"rating_senses"`
-}
-
-func (p Product) ToCsv() ProductCsv {
-	// Concatenate image URLs
-	images := strings.Join(p.Images, "; ")
-
-	// Concatenate description breads
-	descriptionBreads := strings.Join(p.Description.Breads, "; ") // General Itemization Description
-
-	// Concatenate sizes and measurements
-	var sizeChartDetails []string
-	for _, sizeChart := range p.SizeCharts {
-		var measurements []string
-		for _, measurement := range sizeChart.Measurements {
-			measurements = append(measurements, measurement.Type+": "+measurement.Value)
-		}
-		sizeChartDetails = append(sizeChartDetails, sizeChart.Size+" ("+strings.Join(measurements, ", ")+")")
-	}
-	sizeCharts := strings.Join(sizeChartDetails, "; ")
-
-	// Concatenate technologies
-	var technologies []string
-	for _, tech := range p.Technologies {
-		technologies = append(technologies, tech.Name+": "+tech.Desc)
-	}
-	technologiesStr := strings.Join(technologies, "; ")
-
-	// Concatenate reviews
-	var reviews []string
-	for _, review := range p.Reviews {
-		reviews = append(reviews, review.AuthorName+" ("+review.DatePublished+"): "+review.Body+" ["+review.RatingValue+"/"+review.BestRating+"]")
-	}
-	reviewsStr := strings.Join(reviews, "; ")
-
-	// Concatenate rating senses
-	var ratingSenses []string
-	for _, ratingSense := range p.RatingSenses {
-		ratingSenses = append(ratingSenses, ratingSense.Type+": "+ratingSense.Value)
-	}
-	ratingSensesStr := strings.Join(ratingSenses, "; ")
-
-	// Convert to ProductCsv
-	pCsv := ProductCsv{
-		Name:                p.Name,
-		PriceWithTax:        p.Price.WithTax,
-		PriceWithoutTax:     p.Price.WithoutTax,
-		DiscountType:        p.Price.DiscountType,
-		URL:                 p.URL,
-		Images:              images,
-		Breadcrumb:          p.Breadcrumb,
-		KWs:                 p.KWs,
-		SizeChoiceAvailable: p.SizeChoice.AvailableSize,
-		SizeChoiceSense:     p.SizeChoice.SenseOfTheSize,
-		DescriptionTitle:    p.Description.Title,
-		DescriptionGeneral:  p.Description.General,
-		DescriptionBreads:   descriptionBreads,
-		SizeCharts:          sizeCharts,
-		Technologies:        technologiesStr,
-		ReviewCount:         p.ReviewCount,
-		Reviews:             reviewsStr,
-		Rating:              p.Rating,
-		RecommendedRate:     p.RecommendedRate,
-		RatingSenses:        ratingSensesStr,
-	}
-
-	if len(p.Coordinates) > 0 {
-		pCsv.CoordinatesProductName = p.Coordinates[0].ProductName
-		pCsv.CoordinatesProductURL = p.Coordinates[0].ProductURL
-		pCsv.CoordinatesProductImage = p.Coordinates[0].ProductImage
-		pCsv.CoordinatesProductPriceWithTax = p.Coordinates[0].ProductPrice.WithTax
-		pCsv.CoordinatesProductPriceWithoutTax = p.Coordinates[0].ProductPrice.WithoutTax
-		pCsv.CoordinatesProductDiscountType = p.Coordinates[0].ProductPrice.DiscountType
-	}
-
-	return pCsv
+	// AverageRating, RecommendedPercent and RatingHistogram are the numeric values of the rating summary
+	AverageRating      float64       `json:"average_rating" jsonschema:"minimum=0,maximum=5"`
+	RecommendedPercent int           `json:"recommended_percent" jsonschema:"minimum=0,maximum=100"`
+	RatingHistogram    []RatingCount `json:"rating_histogram"`
+	// RatingMissingReason tells why Rating, RecommendedRate and RatingSenses are empty
+	RatingMissingReason RatingMissingReason `json:"rating_missing_reason,omitempty" jsonschema:"enum=no_reviews|fetch_failed|malformed_payload|no_summary|no_rating"`
 }
@@ -1,32 +1,418 @@
 package dto
 
-type ProductCsv struct {
-	Name string `csv:"name" json:"name"`
-	URL  string `csv:"url" json:"url"`
-	// ModelCode                         string `csv:"model_code" json:"model_code"`
-	// ArticleCode                       string `csv:"article_code" json:"article_code"`
-	PriceWithTax                      string `csv:"price_with_tax" json:"price_with_tax"`
-	PriceWithoutTax                   string `csv:"price_without_tax" json:"price_without_tax"`
-	DiscountType                      string `csv:"discount_type" json:"discount_type"`
-	Images                            string `csv:"images" json:"images"` // Concatenated string of image URLs
-	Breadcrumb                        string `csv:"breadcrumb" json:"breadcrumb"`
-	KWs                               string `csv:"kws" json:"kws"`
-	SizeChoiceAvailable               string `csv:"available_size" json:"size_choice_available"`
-	SizeChoiceSense                   string `csv:"sense_of_the_size" json:"size_choice_sense"`
-	CoordinatesProductName            string `csv:"coordinates_product_name" json:"coordinates_product_name"`
-	CoordinatesProductURL             string `csv:"coordinates_product_url" json:"coordinates_product_url"`
-	CoordinatesProductImage           string `csv:"coordinates_product_image" json:"coordinates_product_image"`
-	CoordinatesProductPriceWithTax    string `csv:"coordinates_product_price_with_tax" json:"coordinates_product_price_with_tax"`
-	CoordinatesProductPriceWithoutTax string `csv:"coordinates_product_price_without_tax" json:"coordinates_product_price_without_tax"`
-	CoordinatesProductDiscountType    string `csv:"coordinates_product_discount_type" json:"coordinates_product_discount_type"`
-	DescriptionTitle                  string `csv:"description_title" json:"description_title"`
-	DescriptionGeneral                string `csv:"general_description" json:"description_general"`
-	DescriptionBreads                 string `csv:"general_itemization_description" json:"description_breads"` // Concatenated string of breads
-	SizeCharts                        string `csv:"size_charts" json:"size_charts"`                            // Concatenated string of sizes and measurements
-	Technologies                      string `csv:"special_function" json:"technologies"`                      // Concatenated string of technologies
-	ReviewCount                       string `csv:"review_count" json:"review_count"`
-	Reviews                           string `csv:"reviews" json:"reviews"` // Concatenated string of reviews
-	Rating                            string `csv:"rating" json:"rating"`
-	RecommendedRate                   string `csv:"recommended_rate" json:"recommended_rate"`
-	RatingSenses                      string `csv:"rating_senses" json:"rating_senses"` // Concatenated string of rating senses
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// CsvMultiValue selects how multi-valued fields (coordinates, skus, categories)
+// are flattened into CSV columns
+type CsvMultiValue string
+
+const (
+	// CsvMultiValueFirst keeps only the first item of each multi-valued field
+	CsvMultiValueFirst CsvMultiValue = "first"
+	// CsvMultiValueIndexed spreads the items over numbered columns, up to MaxItems
+	CsvMultiValueIndexed CsvMultiValue = "indexed"
+	// CsvMultiValueJSON encodes all the items as a JSON array in a single cell
+	CsvMultiValueJSON CsvMultiValue = "json"
+)
+
+type CsvOptions struct {
+	MultiValue CsvMultiValue
+	MaxItems   int            // Number of indexed columns per multi-valued field
+	Columns    []CsvColumnMap // Column mapping, all the columns in the default order when empty
+}
+
+// CsvColumnMap selects a column, or a multi-valued field, and renames its header.
+// Fields selects, orders and renames the item columns of a multi-valued field,
+// all of them are kept when it is empty.
+type CsvColumnMap struct {
+	Key    string         `json:"key"`
+	Header string         `json:"header,omitempty"`
+	Fields []CsvColumnMap `json:"fields,omitempty"`
+}
+
+// CsvColumn is a single CSV column and the way to extract its value from a product
+type CsvColumn struct {
+	Header string
+	Value  func(p Product) string
+}
+
+// csvSource provides the columns of a single or multi-valued product field
+type csvSource interface {
+	columns(opts CsvOptions, m CsvColumnMap) ([]CsvColumn, error)
+}
+
+// csvSingle is a single-valued product field
+type csvSingle func(p Product) string
+
+// csvMulti is a multi-valued product field
+type csvMulti[T any] struct {
+	items  func(p Product) []T
+	fields []csvField[T]
+}
+
+// csvField is a column of a single item of a multi-valued field
+type csvField[T any] struct {
+	key   string
+	value func(item T) string
+}
+
+// CsvColumns returns the CSV columns of a product for the given options
+func CsvColumns(opts CsvOptions) ([]CsvColumn, error) {
+	if opts.MultiValue == "" {
+		opts.MultiValue = CsvMultiValueFirst
+	}
+
+	switch opts.MultiValue {
+	case CsvMultiValueFirst, CsvMultiValueJSON:
+	case CsvMultiValueIndexed:
+		if opts.MaxItems < 1 {
+			return nil, fmt.Errorf("csv max items must be at least 1, got %d", opts.MaxItems)
+		}
+	default:
+		return nil, fmt.Errorf("unknown csv multi-value strategy: %q", opts.MultiValue)
+	}
+
+	mapping := opts.Columns
+	if len(mapping) == 0 {
+		for _, key := range csvDefaultKeys {
+			mapping = append(mapping, CsvColumnMap{Key: key})
+		}
+	}
+
+	var columns []CsvColumn
+	for _, m := range mapping {
+		source, ok := csvSources[m.Key]
+		if !ok {
+			return nil, fmt.Errorf("unknown csv column: %q", m.Key)
+		}
+
+		sourceColumns, err := source.columns(opts, m)
+		if err != nil {
+			return nil, err
+		}
+		columns = append(columns, sourceColumns...)
+	}
+
+	return columns, nil
+}
+
+// CsvHeader returns the header row of the given columns
+func CsvHeader(columns []CsvColumn) []string {
+	header := make([]string, 0, len(columns))
+	for _, column := range columns {
+		header = append(header, column.Header)
+	}
+	return header
+}
+
+// ToCsv returns the CSV record of the product for the given columns
+func (p Product) ToCsv(columns []CsvColumn) []string {
+	record := make([]string, 0, len(columns))
+	for _, column := range columns {
+		record = append(record, column.Value(p))
+	}
+	return record
+}
+
+func (s csvSingle) columns(_ CsvOptions, m CsvColumnMap) ([]CsvColumn, error) {
+	if len(m.Fields) > 0 {
+		return nil, fmt.Errorf("csv column %q has no fields", m.Key)
+	}
+
+	header := m.Header
+	if header == "" {
+		header = m.Key
+	}
+
+	return []CsvColumn{{Header: header, Value: s}}, nil
+}
+
+func (s csvMulti[T]) columns(opts CsvOptions, m CsvColumnMap) ([]CsvColumn, error) {
+	fieldsMapping := m.Fields
+	if len(fieldsMapping) == 0 {
+		for _, field := range s.fields {
+			fieldsMapping = append(fieldsMapping, CsvColumnMap{Key: field.key})
+		}
+	}
+
+	var fields []csvField[T]
+	for _, fm := range fieldsMapping {
+		field, ok := s.field(fm.Key)
+		if !ok {
+			return nil, fmt.Errorf("unknown csv field %q of column %q", fm.Key, m.Key)
+		}
+		fields = append(fields, field)
+	}
+
+	if opts.MultiValue == CsvMultiValueJSON {
+		header := m.Header
+		if header == "" {
+			header = m.Key
+		}
+
+		return []CsvColumn{{
+			Header: header,
+			Value: func(p Product) string {
+				values := s.items(p)
+				if len(values) == 0 {
+					return ""
+				}
+				// Without a fields mapping the items are kept as they are
+				if len(m.Fields) == 0 {
+					b, err := json.Marshal(values)
+					if err != nil {
+						return ""
+					}
+					return string(b)
+				}
+				return s.jsonItems(values, fieldsMapping, fields)
+			},
+		}}, nil
+	}
+
+	if opts.MultiValue == CsvMultiValueFirst {
+		return s.itemColumns(m, fieldsMapping, fields, 0, ""), nil
+	}
+
+	var columns []CsvColumn
+	for i := 0; i < opts.MaxItems; i++ {
+		columns = append(columns, s.itemColumns(m, fieldsMapping, fields, i, strconv.Itoa(i+1))...)
+	}
+	return columns, nil
+}
+
+// jsonItems returns the JSON array of the items with only the mapped fields, keyed by their header in the mapping order
+func (s csvMulti[T]) jsonItems(values []T, fieldsMapping []CsvColumnMap, fields []csvField[T]) string {
+	var b strings.Builder
+	b.WriteString("[")
+	for i, item := range values {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		b.WriteString("{")
+		for fi, field := range fields {
+			name := fieldsMapping[fi].Header
+			if name == "" {
+				name = field.key
+			}
+			key, _ := json.Marshal(name)
+			value, _ := json.Marshal(field.value(item))
+			if fi > 0 {
+				b.WriteString(",")
+			}
+			b.Write(key)
+			b.WriteString(":")
+			b.Write(value)
+		}
+		b.WriteString("}")
+	}
+	b.WriteString("]")
+	return b.String()
+}
+
+func (s csvMulti[T]) field(key string) (csvField[T], bool) {
+	for _, field := range s.fields {
+		if field.key == key {
+			return field, true
+		}
+	}
+	return csvField[T]{}, false
+}
+
+// itemColumns returns the columns of the item at index, blank when the product has fewer items.
+// Headers are the snake case keys unless the mapping renames the field or its items.
+func (s csvMulti[T]) itemColumns(m CsvColumnMap, fieldsMapping []CsvColumnMap, fields []csvField[T], index int, number string) []CsvColumn {
+	var columns []CsvColumn
+	for fi, field := range fields {
+		field, fm := field, fieldsMapping[fi]
+
+		var header string
+		if m.Header == "" && fm.Header == "" {
+			header = strings.Join(nonEmpty(m.Key, number, field.key), "_")
+		} else {
+			prefix, name := m.Header, fm.Header
+			if prefix == "" {
+				prefix = m.Key
+			}
+			if name == "" {
+				name = field.key
+			}
+			header = prefix + number + " " + name
+		}
+
+		columns = append(columns, CsvColumn{
+			Header: header,
+			Value: func(p Product) string {
+				values := s.items(p)
+				if index >= len(values) {
+					return ""
+				}
+				return field.value(values[index])
+			},
+		})
+	}
+	return columns
+}
+
+func nonEmpty(values ...string) []string {
+	var result []string
+	for _, v := range values {
+		if v != "" {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// csvDefaultKeys is the order of the columns when there is no column mapping
+var csvDefaultKeys = []string{
+	"name", "model_code", "article_code", "url",
+	"color_name", "brand_name", "sport_name", "gender_name", "genre_name",
+	"release_date", "item_status", "limited", "optional_label", "fixed_price", "discount_price",
+	"price_with_tax", "price_without_tax", "currency", "tax_rate", "tax_included",
+	"display_price_with_tax", "display_price_without_tax", "discount_type",
+	"images", "breadcrumb", "kws", "available_size", "sense_of_the_size",
+	"coordinates",
+	"description_title", "general_description", "general_itemization_description",
+	"size_charts", "special_function",
+	"review_count", "reviews", "rating", "recommended_rate", "rating_senses", "rating_missing_reason",
+	"average_rating", "recommended_percent", "rating_histogram",
+	"skus", "categories", "image_details",
+}
+
+var csvSources = map[string]csvSource{
+	"name":              csvSingle(func(p Product) string { return p.Name }),
+	"model_code":        csvSingle(func(p Product) string { return p.ModelCode }),
+	"article_code":      csvSingle(func(p Product) string { return p.ArticleCode }),
+	"url":               csvSingle(func(p Product) string { return p.URL }),
+	"color_name":        csvSingle(func(p Product) string { return p.ColorName }),
+	"brand_name":        csvSingle(func(p Product) string { return p.BrandName }),
+	"sport_name":        csvSingle(func(p Product) string { return p.SportName }),
+	"gender_name":       csvSingle(func(p Product) string { return p.GenderName }),
+	"genre_name":        csvSingle(func(p Product) string { return p.GenreName }),
+	"release_date":      csvSingle(func(p Product) string { return p.ReleaseDate }),
+	"item_status":       csvSingle(func(p Product) string { return p.ItemStatus }),
+	"limited":           csvSingle(func(p Product) string { return strconv.FormatBool(p.Limited) }),
+	"optional_label":    csvSingle(func(p Product) string { return p.OptionalLabel }),
+	"fixed_price":       csvSingle(func(p Product) string { return strconv.Itoa(p.FixedPrice) }),
+	"discount_price":    csvSingle(func(p Product) string { return strconv.Itoa(p.DiscountPrice) }),
+	"price_with_tax":    csvSingle(func(p Product) string { return strconv.Itoa(p.Price.WithTax) }),
+	"price_without_tax": csvSingle(func(p Product) string { return strconv.Itoa(p.Price.WithoutTax) }),
+	"currency":          csvSingle(func(p Product) string { return p.Price.Currency }),
+	"tax_rate":          csvSingle(func(p Product) string { return strconv.FormatFloat(p.Price.TaxRate, 'f', -1, 64) }),
+	"tax_included":      csvSingle(func(p Product) string { return strconv.FormatBool(p.Price.TaxIncluded) }),
+	// Prices as displayed by the store
+	"display_price_with_tax":    csvSingle(func(p Product) string { return p.Price.DisplayWithTax }),
+	"display_price_without_tax": csvSingle(func(p Product) string { return p.Price.DisplayWithoutTax }),
+	"discount_type":             csvSingle(func(p Product) string { return p.Price.DiscountType }),
+	// Concatenated string of image URLs
+	"images":            csvSingle(func(p Product) string { return strings.Join(p.Images, "; ") }),
+	"breadcrumb":        csvSingle(func(p Product) string { return p.Breadcrumb }),
+	"kws":               csvSingle(func(p Product) string { return p.KWs }),
+	"available_size":    csvSingle(func(p Product) string { return p.SizeChoice.AvailableSize }),
+	"sense_of_the_size": csvSingle(func(p Product) string { return p.SizeChoice.SenseOfTheSize }),
+	"coordinates": csvMulti[Coordinate]{
+		items: func(p Product) []Coordinate { return p.Coordinates },
+		fields: []csvField[Coordinate]{
+			{"product_name", func(c Coordinate) string { return c.ProductName }},
+			{"product_url", func(c Coordinate) string { return c.ProductURL }},
+			{"product_image", func(c Coordinate) string { return c.ProductImage }},
+			{"product_price_with_tax", func(c Coordinate) string { return strconv.Itoa(c.ProductPrice.WithTax) }},
+			{"product_price_without_tax", func(c Coordinate) string { return strconv.Itoa(c.ProductPrice.WithoutTax) }},
+			{"product_display_price_with_tax", func(c Coordinate) string { return c.ProductPrice.DisplayWithTax }},
+			{"product_discount_type", func(c Coordinate) string { return c.ProductPrice.DiscountType }},
+		},
+	},
+	"description_title":   csvSingle(func(p Product) string { return p.Description.Title }),
+	"general_description": csvSingle(func(p Product) string { return p.Description.General }),
+	// Concatenated string of breads, general itemization description
+	"general_itemization_description": csvSingle(func(p Product) string { return strings.Join(p.Description.Breads, "; ") }),
+	// Concatenated string of sizes and measurements
+	"size_charts": csvSingle(func(p Product) string {
+		var sizeChartDetails []string
+		for _, sizeChart := range p.SizeCharts {
+			var measurements []string
+			for _, measurement := range sizeChart.Measurements {
+				measurements = append(measurements, measurement.Type+": "+measurement.Value)
+			}
+			sizeChartDetails = append(sizeChartDetails, sizeChart.Size+" ("+strings.Join(measurements, ", ")+")")
+		}
+		return strings.Join(sizeChartDetails, "; ")
+	}),
+	// Concatenated string of technologies
+	"special_function": csvSingle(func(p Product) string {
+		var technologies []string
+		for _, tech := range p.Technologies {
+			technologies = append(technologies, tech.Name+": "+tech.Desc)
+		}
+		return strings.Join(technologies, "; ")
+	}),
+	"review_count": csvSingle(func(p Product) string { return p.ReviewCount }),
+	// Concatenated string of reviews
+	"reviews": csvSingle(func(p Product) string {
+		var reviews []string
+		for _, review := range p.Reviews {
+			reviews = append(reviews, review.AuthorName+" ("+review.DatePublished+"): "+review.Body+" ["+review.RatingValue+"/"+review.BestRating+"]")
+		}
+		return strings.Join(reviews, "; ")
+	}),
+	"rating":                csvSingle(func(p Product) string { return p.Rating }),
+	"recommended_rate":      csvSingle(func(p Product) string { return p.RecommendedRate }),
+	"rating_missing_reason": csvSingle(func(p Product) string { return string(p.RatingMissingReason) }),
+	// Concatenated string of rating senses
+	"rating_senses": csvSingle(func(p Product) string {
+		var ratingSenses []string
+		for _, ratingSense := range p.RatingSenses {
+			ratingSenses = append(ratingSenses, ratingSense.Type+": "+ratingSense.Value)
+		}
+		return strings.Join(ratingSenses, "; ")
+	}),
+	"average_rating":      csvSingle(func(p Product) string { return strconv.FormatFloat(p.AverageRating, 'f', -1, 64) }),
+	"recommended_percent": csvSingle(func(p Product) string { return strconv.Itoa(p.RecommendedPercent) }),
+	// Concatenated string of the star rating histogram, e.g. "5: 11; 4: 3"
+	"rating_histogram": csvSingle(func(p Product) string {
+		var counts []string
+		for _, ratingCount := range p.RatingHistogram {
+			counts = append(counts, strconv.Itoa(ratingCount.Stars)+": "+strconv.Itoa(ratingCount.Count))
+		}
+		return strings.Join(counts, "; ")
+	}),
+	"skus": csvMulti[Sku]{
+		items: func(p Product) []Sku { return p.Skus },
+		fields: []csvField[Sku]{
+			{"size_name", func(s Sku) string { return s.SizeName }},
+			{"code", func(s Sku) string { return s.Code }},
+			{"canonical_size", func(s Sku) string { return s.CanonicalSize.Code }},
+			{"size_system", func(s Sku) string { return string(s.CanonicalSize.System) }},
+			{"is_stock", func(s Sku) string { return strconv.FormatBool(s.Status.IsStockEc) }},
+			{"is_stock_store", func(s Sku) string { return strconv.FormatBool(s.Status.IsStockStore) }},
+			{"is_sold_out", func(s Sku) string { return strconv.FormatBool(s.Status.IsSoldOut) }},
+			{"stock_message", func(s Sku) string { return s.StockMessage }},
+			{"can_add_to_cart", func(s Sku) string { return strconv.FormatBool(s.CanAddToCart) }},
+		},
+	},
+	"image_details": csvMulti[Image]{
+		items: func(p Product) []Image { return p.ImageDetails },
+		fields: []csvField[Image]{
+			{"large", func(i Image) string { return i.Large }},
+			{"medium", func(i Image) string { return i.Medium }},
+			{"small", func(i Image) string { return i.Small }},
+			{"caption", func(i Image) string { return i.Caption }},
+			{"view", func(i Image) string { return i.View }},
+			{"order", func(i Image) string { return strconv.Itoa(i.Order) }},
+			{"listing", func(i Image) string { return i.Listing }},
+		},
+	},
+	"categories": csvMulti[Category]{
+		items: func(p Product) []Category { return p.Categories },
+		fields: []csvField[Category]{
+			{"label", func(c Category) string { return c.Label }},
+			{"link", func(c Category) string { return c.Link }},
+		},
+	},
 }
@@ -0,0 +1,47 @@
+package dto
+
+import "testing"
+
+func TestCsvColumnsJSONFields(t *testing.T) {
+	product := Product{Skus: []Sku{{SizeName: "S"}, {SizeName: "M"}}}
+
+	tests := []struct {
+		name    string
+		mapping CsvColumnMap
+		header  string
+		want    string
+	}{
+		{
+			name:    "renamed field",
+			mapping: CsvColumnMap{Key: "skus", Header: "SKU", Fields: []CsvColumnMap{{Key: "size_name", Header: "サイズ"}}},
+			header:  "SKU",
+			want:    `[{"サイズ":"S"},{"サイズ":"M"}]`,
+		},
+		{
+			name:    "field keys",
+			mapping: CsvColumnMap{Key: "skus", Fields: []CsvColumnMap{{Key: "size_name"}}},
+			header:  "skus",
+			want:    `[{"size_name":"S"},{"size_name":"M"}]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			columns, err := CsvColumns(CsvOptions{MultiValue: CsvMultiValueJSON, Columns: []CsvColumnMap{tt.mapping}})
+			if err != nil {
+				t.Fatalf("CsvColumns() error = %v", err)
+			}
+			if len(columns) != 1 || columns[0].Header != tt.header {
+				t.Fatalf("CsvColumns() headers = %v, want [%s]", CsvHeader(columns), tt.header)
+			}
+			if got := columns[0].Value(product); got != tt.want {
+				t.Errorf("value = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	_, err := CsvColumns(CsvOptions{MultiValue: CsvMultiValueJSON, Columns: []CsvColumnMap{{Key: "skus", Fields: []CsvColumnMap{{Key: "nope"}}}}})
+	if err == nil {
+		t.Error("CsvColumns() with an unknown field error = nil")
+	}
+}
@@ -0,0 +1,44 @@
+package dto
+
+import "time"
+
+type RunStatus string
+
+const (
+	RunStatusSuccess RunStatus = "success"
+	// RunStatusPartial is a finished run where some products failed to crawl or to validate
+	RunStatusPartial RunStatus = "partial"
+	RunStatusFailure RunStatus = "failure"
+)
+
+// Failure is a page that failed to crawl
+type Failure struct {
+	URL   string `json:"url"`
+	Cause string `json:"cause"`
+}
+
+// Violation is a product that doesn't match the product JSON Schema
+type Violation struct {
+	ArticleCode string `json:"article_code"`
+	Location    string `json:"location"` // JSON pointer of the invalid value
+	Message     string `json:"message"`
+}
+
+// RunReport is the outcome of a crawl run
+type RunReport struct {
+	RunID           string      `json:"run_id"`
+	Store           string      `json:"store"`
+	Status          RunStatus   `json:"status"`
+	Error           string      `json:"error,omitempty"`
+	StartedAt       time.Time   `json:"started_at"`
+	FinishedAt      time.Time   `json:"finished_at"`
+	Duration        string      `json:"duration"`
+	ProductCount    int         `json:"product_count"`
+	FailureCount    int         `json:"failure_count"`
+	Violations      []Violation `json:"violations,omitempty"` // Set when the products are validated
+	Outputs         []string    `json:"outputs"`              // Files and locations written by the exporters
+	ReportFile      string      `json:"report_file"`
+	FailureManifest string      `json:"failure_manifest"`
+	Uploads         []string    `json:"uploads,omitempty"` // Locations of the uploaded files, set by the uploader
+	Failures        []Failure   `json:"-"`                 // Written to the failure manifest
+}
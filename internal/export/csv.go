@@ -0,0 +1,95 @@
+package export
+
+import (
+	"encoding/csv"
+	"log/slog"
+	"os"
+
+	"vcrawler/internal/definition"
+	"vcrawler/internal/dto"
+)
+
+type CsvOptions struct {
+	dto.CsvOptions
+	Encoding    CsvEncoding
+	Fallback    string // Replaces the characters that can't be encoded, DefaultCsvFallback when empty
+	StripBreaks bool   // Replaces the <br /> tags of the general description with new lines
+}
+
+type csvExporter struct {
+	fileName string
+	options  CsvOptions
+}
+
+// GetCsvExporter returns the CSV exporter, or an error when the fallback can't be written in the encoding
+func GetCsvExporter(fileName string, options CsvOptions) (definition.Exporter, error) {
+	if options.Fallback == "" {
+		options.Fallback = DefaultCsvFallback
+	}
+	if err := options.Encoding.checkFallback(options.Fallback); err != nil {
+		return nil, err
+	}
+
+	return &csvExporter{fileName: fileName, options: options}, nil
+}
+
+func (e *csvExporter) Outputs() []string {
+	return []string{e.fileName}
+}
+
+func (e *csvExporter) Export(products []dto.Product) error {
+	columns, err := dto.CsvColumns(e.options.CsvOptions)
+	if err != nil {
+		return err
+	}
+
+	csvFile, err := os.Create(e.fileName)
+	if err != nil {
+		return err
+	}
+	defer csvFile.Close()
+
+	ew, encoder, err := e.options.Encoding.encodingWriter(csvFile)
+	if err != nil {
+		return err
+	}
+
+	w := csv.NewWriter(ew)
+	if err := w.Write(e.encode(dto.CsvHeader(columns))); err != nil {
+		return err
+	}
+
+	for _, product := range products {
+		if e.options.StripBreaks {
+			product.Description.General = stripBreaks(product.Description.General)
+		}
+
+		if err := w.Write(e.encode(product.ToCsv(columns))); err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+
+	if err := encoder.Close(); err != nil {
+		return err
+	}
+
+	slog.Info("products data saved to", "file", e.fileName, "encoding", e.options.Encoding)
+	return nil
+}
+
+// encode replaces the characters of the record that can't be encoded in the CSV encoding
+func (e *csvExporter) encode(record []string) []string {
+	if e.options.Encoding != CsvEncodingShiftJIS {
+		return record
+	}
+
+	for i, field := range record {
+		record[i] = toShiftJIS(field, e.options.Fallback)
+	}
+	return record
+}
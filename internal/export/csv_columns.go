@@ -0,0 +1,122 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"vcrawler/internal/dto"
+)
+
+const (
+	// CsvColumnsAll keeps every column in the default order
+	CsvColumnsAll = "all"
+	// CsvColumnsTechnicalTest is the layout of the technical test deliverable
+	CsvColumnsTechnicalTest = "technical-test"
+	// CsvColumnsJa is the client deliverable layout with Japanese headers
+	CsvColumnsJa = "ja"
+)
+
+var csvColumnPresets = map[string][]dto.CsvColumnMap{
+	CsvColumnsAll: nil,
+	CsvColumnsTechnicalTest: {
+		{Key: "name"},
+		{Key: "url"},
+		{Key: "price_with_tax"},
+		{Key: "price_without_tax"},
+		{Key: "discount_type"},
+		{Key: "images"},
+		{Key: "breadcrumb"},
+		{Key: "kws"},
+		{Key: "available_size"},
+		{Key: "sense_of_the_size"},
+		{Key: "coordinates", Fields: []dto.CsvColumnMap{
+			{Key: "product_name"},
+			{Key: "product_url"},
+			{Key: "product_image"},
+			{Key: "product_price_with_tax"},
+			{Key: "product_price_without_tax"},
+			{Key: "product_discount_type"},
+		}},
+		{Key: "description_title"},
+		{Key: "general_description"},
+		{Key: "general_itemization_description"},
+		{Key: "size_charts"},
+		{Key: "special_function"},
+		{Key: "review_count"},
+		{Key: "reviews"},
+		{Key: "rating"},
+		{Key: "recommended_rate"},
+		{Key: "rating_senses"},
+	},
+	CsvColumnsJa: {
+		{Key: "article_code", Header: "品番"},
+		{Key: "model_code", Header: "モデルコード"},
+		{Key: "name", Header: "商品名"},
+		{Key: "color_name", Header: "カラー"},
+		{Key: "brand_name", Header: "ブランド"},
+		{Key: "sport_name", Header: "スポーツ"},
+		{Key: "gender_name", Header: "性別"},
+		{Key: "release_date", Header: "発売日"},
+		{Key: "limited", Header: "限定"},
+		{Key: "price_with_tax", Header: "価格（税込）"},
+		{Key: "price_without_tax", Header: "価格（税抜）"},
+		{Key: "discount_type", Header: "価格区分"},
+		{Key: "url", Header: "商品URL"},
+		{Key: "breadcrumb", Header: "パンくず"},
+		{Key: "categories", Header: "カテゴリ", Fields: []dto.CsvColumnMap{
+			{Key: "label", Header: "名称"},
+			{Key: "link", Header: "URL"},
+		}},
+		{Key: "kws", Header: "キーワード"},
+		{Key: "description_title", Header: "説明タイトル"},
+		{Key: "general_description", Header: "商品説明"},
+		{Key: "general_itemization_description", Header: "商品説明（箇条書き）"},
+		{Key: "special_function", Header: "機能"},
+		{Key: "available_size", Header: "在庫ありサイズ"},
+		{Key: "sense_of_the_size", Header: "サイズ感"},
+		{Key: "skus", Header: "SKU", Fields: []dto.CsvColumnMap{
+			{Key: "size_name", Header: "サイズ"},
+			{Key: "code", Header: "コード"},
+			{Key: "is_stock", Header: "EC在庫"},
+			{Key: "is_stock_store", Header: "店舗在庫"},
+			{Key: "is_sold_out", Header: "売り切れ"},
+			{Key: "stock_message", Header: "在庫メッセージ"},
+		}},
+		{Key: "size_charts", Header: "サイズ表"},
+		{Key: "review_count", Header: "レビュー数"},
+		{Key: "rating", Header: "評価"},
+		{Key: "recommended_rate", Header: "おすすめ率"},
+		{Key: "rating_senses", Header: "評価項目"},
+		{Key: "reviews", Header: "レビュー"},
+		{Key: "coordinates", Header: "コーディネート", Fields: []dto.CsvColumnMap{
+			{Key: "product_name", Header: "商品名"},
+			{Key: "product_url", Header: "商品URL"},
+			{Key: "product_image", Header: "画像URL"},
+			{Key: "product_price_with_tax", Header: "価格（税込）"},
+			{Key: "product_price_without_tax", Header: "価格（税抜）"},
+			{Key: "product_discount_type", Header: "価格区分"},
+		}},
+		{Key: "images", Header: "画像URL"},
+	},
+}
+
+// GetCsvColumns returns the column mapping of a built-in preset,
+// or reads it from a JSON file when there is no preset with that name
+func GetCsvColumns(presetOrFile string) ([]dto.CsvColumnMap, error) {
+	if columns, ok := csvColumnPresets[presetOrFile]; ok {
+		return columns, nil
+	}
+
+	b, err := os.ReadFile(presetOrFile)
+	if err != nil {
+		return nil, fmt.Errorf("csv columns is neither a preset nor a readable file: %w", err)
+	}
+
+	var columns []dto.CsvColumnMap
+	if err := json.Unmarshal(b, &columns); err != nil {
+		return nil, fmt.Errorf("error at unmarshalling csv columns: %w", err)
+	}
+
+	return columns, nil
+}
@@ -0,0 +1,26 @@
+package export
+
+import "testing"
+
+func TestGetCsvExporterFallback(t *testing.T) {
+	tests := []struct {
+		name     string
+		encoding CsvEncoding
+		fallback string
+		wantErr  bool
+	}{
+		{"default fallback", CsvEncodingShiftJIS, "", false},
+		{"japanese fallback", CsvEncodingShiftJIS, "〓", false},
+		{"emoji fallback in shift_jis", CsvEncodingShiftJIS, "😀", true},
+		{"emoji fallback in utf-8", CsvEncodingUTF8, "😀", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := GetCsvExporter("products.csv", CsvOptions{Encoding: tt.encoding, Fallback: tt.fallback})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetCsvExporter() error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package export
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"vcrawler/internal/definition"
+	"vcrawler/internal/dto"
+)
+
+// elasticTemplate is the index template of the products, with the kuromoji
+// Japanese analyzer for the name, description and keywords
+//
+//go:embed elastic_template.json
+var elasticTemplate []byte
+
+const defaultElasticBatchSize = 500
+
+type ElasticOptions struct {
+	URL       string // Elasticsearch/OpenSearch URL, the bulk requests are written to File when empty
+	File      string // NDJSON bulk file for offline loading
+	Index     string
+	Username  string
+	Password  string
+	BatchSize int // Number of documents per bulk request
+}
+
+type elasticExporter struct {
+	options ElasticOptions
+	client  *http.Client
+}
+
+// GetElasticExporter returns an exporter that indexes the products with their article code as document id
+func GetElasticExporter(options ElasticOptions) definition.Exporter {
+	if options.BatchSize < 1 {
+		options.BatchSize = defaultElasticBatchSize
+	}
+
+	return &elasticExporter{
+		options: options,
+		client:  &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+func (e *elasticExporter) Outputs() []string {
+	if e.options.URL == "" {
+		return []string{e.options.File, e.templateFile()}
+	}
+	return []string{strings.TrimSuffix(e.options.URL, "/") + "/" + e.options.Index}
+}
+
+func (e *elasticExporter) Export(products []dto.Product) error {
+	template, err := e.template()
+	if err != nil {
+		return err
+	}
+
+	if e.options.URL == "" {
+		return e.exportFile(template, products)
+	}
+
+	if err := e.request(http.MethodPut, "/_index_template/"+e.options.Index, "application/json", template, nil); err != nil {
+		return fmt.Errorf("error at putting index template: %w", err)
+	}
+
+	var failed int
+	for start := 0; start < len(products); start += e.options.BatchSize {
+		end := min(start+e.options.BatchSize, len(products))
+
+		body, err := e.bulkBody(products[start:end])
+		if err != nil {
+			return err
+		}
+
+		var resp elasticBulkResponse
+		if err := e.request(http.MethodPost, "/_bulk", "application/x-ndjson", body, &resp); err != nil {
+			return fmt.Errorf("error at bulk indexing: %w", err)
+		}
+
+		for _, item := range resp.Items {
+			for _, result := range item {
+				if result.Error != nil {
+					failed++
+					slog.Error("error at indexing product", "id", result.ID, "type", result.Error.Type, "reason", result.Error.Reason)
+				}
+			}
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d products failed to index", failed, len(products))
+	}
+
+	slog.Info("products data indexed to", "url", e.options.URL, "index", e.options.Index, "count", len(products))
+	return nil
+}
+
+// exportFile writes the bulk requests to the NDJSON file, and the index template next to it
+func (e *elasticExporter) exportFile(template []byte, products []dto.Product) error {
+	body, err := e.bulkBody(products)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(e.options.File, body, 0644); err != nil {
+		return err
+	}
+
+	templateFile := e.templateFile()
+	if err := os.WriteFile(templateFile, template, 0644); err != nil {
+		return err
+	}
+
+	slog.Info("products data saved to", "file", e.options.File, "template", templateFile)
+	return nil
+}
+
+func (e *elasticExporter) templateFile() string {
+	return strings.TrimSuffix(e.options.File, ".ndjson") + ".template.json"
+}
+
+// template returns the index template for the index
+func (e *elasticExporter) template() ([]byte, error) {
+	var template map[string]any
+	if err := json.Unmarshal(elasticTemplate, &template); err != nil {
+		return nil, err
+	}
+
+	template["index_patterns"] = []string{e.options.Index}
+	return json.MarshalIndent(template, "", "  ")
+}
+
+func (e *elasticExporter) bulkBody(products []dto.Product) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+
+	for _, product := range products {
+		action := map[string]elasticBulkAction{
+			"index": {Index: e.options.Index, ID: product.ArticleCode},
+		}
+		if err := enc.Encode(action); err != nil {
+			return nil, err
+		}
+		if err := enc.Encode(product); err != nil {
+			return nil, err
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (e *elasticExporter) request(method, path, contentType string, body []byte, result any) error {
+	req, err := http.NewRequest(method, strings.TrimSuffix(e.options.URL, "/")+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", contentType)
+	if e.options.Username != "" {
+		req.SetBasicAuth(e.options.Username, e.options.Password)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s: %s", resp.Status, respBody)
+	}
+
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(respBody, result)
+}
+
+type elasticBulkAction struct {
+	Index string `json:"_index"`
+	ID    string `json:"_id"`
+}
+
+type elasticBulkResponse struct {
+	Errors bool                               `json:"errors"`
+	Items  []map[string]elasticBulkItemResult `json:"items"`
+}
+
+type elasticBulkItemResult struct {
+	ID     string `json:"_id"`
+	Status int    `json:"status"`
+	Error  *struct {
+		Type   string `json:"type"`
+		Reason string `json:"reason"`
+	} `json:"error"`
+}
@@ -0,0 +1,141 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// CsvEncoding is the character encoding of the CSV file
+type CsvEncoding string
+
+const (
+	CsvEncodingUTF8    CsvEncoding = "utf-8"
+	CsvEncodingUTF8BOM CsvEncoding = "utf-8-bom"
+	// CsvEncodingShiftJIS is Shift_JIS with the Windows (CP932) extensions, as Excel reads it on Japanese Windows
+	CsvEncodingShiftJIS CsvEncoding = "shift_jis"
+)
+
+// DefaultCsvFallback replaces the characters that can't be encoded in Shift_JIS
+const DefaultCsvFallback = "?"
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// GetCsvEncoding returns the encoding with the given name, cp932 and sjis are aliases of Shift_JIS
+func GetCsvEncoding(name string) (CsvEncoding, error) {
+	switch strings.ToLower(name) {
+	case "", "utf-8", "utf8":
+		return CsvEncodingUTF8, nil
+	case "utf-8-bom", "utf8-bom", "utf-8-sig":
+		return CsvEncodingUTF8BOM, nil
+	case "shift_jis", "shift-jis", "sjis", "cp932", "windows-31j":
+		return CsvEncodingShiftJIS, nil
+	}
+
+	return "", fmt.Errorf("unknown csv encoding: %q", name)
+}
+
+// checkFallback returns an error when the fallback can't be written in the encoding
+func (e CsvEncoding) checkFallback(fallback string) error {
+	if e == CsvEncodingShiftJIS && !canShiftJIS(fallback) {
+		return fmt.Errorf("csv fallback %q can't be encoded in shift_jis", fallback)
+	}
+	return nil
+}
+
+// encodingWriter wraps the file writer to write in the encoding. The returned
+// closer flushes the encoder and must be called before closing the file.
+func (e CsvEncoding) encodingWriter(w io.Writer) (io.Writer, io.Closer, error) {
+	switch e {
+	case CsvEncodingUTF8BOM:
+		if _, err := w.Write(utf8BOM); err != nil {
+			return nil, nil, err
+		}
+	case CsvEncodingShiftJIS:
+		tw := transform.NewWriter(w, japanese.ShiftJIS.NewEncoder())
+		return tw, tw, nil
+	}
+
+	return w, io.NopCloser(nil), nil
+}
+
+// shiftJISEquivalents maps the Unicode characters that Shift_JIS decoders
+// disagree on to the code points that CP932 encodes
+var shiftJISEquivalents = map[rune]string{
+	'〜':      "～", // WAVE DASH
+	'−':      "－", // MINUS SIGN
+	'‖':      "∥", // DOUBLE VERTICAL LINE
+	'—':      "―", // EM DASH
+	'¢':      "￠",
+	'£':      "￡",
+	'¬':      "￢",
+	'\u00a0': " ", // NO-BREAK SPACE
+	'™':      "TM",
+	'®':      "(R)",
+	'©':      "(C)",
+}
+
+// toShiftJIS replaces the characters of s that can't be encoded in Shift_JIS.
+// They are mapped to their CP932 equivalents, then stripped of their accents,
+// and replaced with the fallback when nothing else works.
+func toShiftJIS(s, fallback string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if canShiftJIS(string(r)) {
+			b.WriteRune(r)
+			continue
+		}
+
+		if eq, ok := shiftJISEquivalents[r]; ok {
+			b.WriteString(eq)
+			continue
+		}
+
+		if base := stripAccents(r); base != "" && canShiftJIS(base) {
+			b.WriteString(base)
+			continue
+		}
+
+		b.WriteString(fallback)
+	}
+	return b.String()
+}
+
+var shiftJISRunes = map[string]bool{}
+
+func canShiftJIS(s string) bool {
+	ok, cached := shiftJISRunes[s]
+	if !cached {
+		_, err := japanese.ShiftJIS.NewEncoder().String(s)
+		ok = err == nil
+		shiftJISRunes[s] = ok
+	}
+	return ok
+}
+
+// stripAccents returns the base letters of a character without its combining marks, é → e
+func stripAccents(r rune) string {
+	var b strings.Builder
+	for _, d := range norm.NFD.String(string(r)) {
+		if !unicode.Is(unicode.Mn, d) {
+			b.WriteRune(d)
+		}
+	}
+	if b.String() == string(r) {
+		return ""
+	}
+	return b.String()
+}
+
+var breakTag = regexp.MustCompile(`(?i)<br\s*/?>`)
+
+// stripBreaks replaces the <br /> tags of a description with new lines
+func stripBreaks(s string) string {
+	return breakTag.ReplaceAllString(s, "\n")
+}
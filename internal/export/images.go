@@ -0,0 +1,279 @@
+package export
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"vcrawler/internal/definition"
+	"vcrawler/internal/dto"
+)
+
+const (
+	DefaultImagesDir         = "images"
+	DefaultImagesConcurrency = 4
+	DefaultImagesDelay       = 200 * time.Millisecond
+
+	imagesManifest = "manifest.json"
+
+	// imagesManifestEvery is the number of downloads between two saves of the manifest
+	imagesManifestEvery = 20
+)
+
+type ImagesOptions struct {
+	Dir         string        // Directory of the images and of their manifest
+	Concurrency int           // Parallel downloads
+	Delay       time.Duration // Minimum delay between two download starts
+}
+
+// ImageFile is a downloaded image, named by the SHA-256 of its content so identical images are stored once
+type ImageFile struct {
+	URL    string `json:"url"`
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256"`
+	Size   int    `json:"size"`
+}
+
+// ImagesManifest maps the article codes to their downloaded images, in the order of dto.Product.Images
+type ImagesManifest map[string][]ImageFile
+
+type imagesExporter struct {
+	downloader definition.Downloader
+	options    ImagesOptions
+}
+
+// GetImagesExporter returns an exporter that downloads the product images with the store downloader
+func GetImagesExporter(downloader definition.Downloader, options ImagesOptions) definition.Exporter {
+	if options.Dir == "" {
+		options.Dir = DefaultImagesDir
+	}
+	if options.Concurrency <= 0 {
+		options.Concurrency = DefaultImagesConcurrency
+	}
+
+	return &imagesExporter{downloader: downloader, options: options}
+}
+
+func (e *imagesExporter) Outputs() []string {
+	return []string{e.manifestFile()}
+}
+
+func (e *imagesExporter) manifestFile() string {
+	return filepath.Join(e.options.Dir, imagesManifest)
+}
+
+func (e *imagesExporter) Export(products []dto.Product) error {
+	if err := os.MkdirAll(e.options.Dir, 0755); err != nil {
+		return err
+	}
+
+	// The images of the previous runs still on disk are not downloaded again,
+	// and the articles absent from this run keep their previous entries
+	previous, err := e.loadManifest()
+	if err != nil {
+		return err
+	}
+	downloaded := map[string]ImageFile{}
+	for _, files := range previous {
+		for _, file := range files {
+			downloaded[file.URL] = file
+		}
+	}
+
+	var (
+		urls    []string
+		skipped int
+	)
+	for _, product := range products {
+		for _, url := range product.Images {
+			file, ok := downloaded[url]
+			switch {
+			case !ok:
+				downloaded[url] = ImageFile{}
+				urls = append(urls, url)
+			case file.Path != "":
+				skipped++
+			}
+		}
+	}
+	slog.Info("downloading images", "dir", e.options.Dir, "images", len(urls), "skipped", skipped)
+
+	// manifest must be called with mu held once the downloads have started
+	manifest := func() ImagesManifest {
+		manifest := ImagesManifest{}
+		for code, files := range previous {
+			manifest[code] = files
+		}
+		for _, product := range products {
+			var files []ImageFile
+			for _, url := range product.Images {
+				if file, ok := downloaded[url]; ok && file.Path != "" {
+					files = append(files, file)
+				}
+			}
+			if len(files) > 0 {
+				manifest[product.ArticleCode] = files
+			}
+		}
+		return manifest
+	}
+
+	var (
+		mu   sync.Mutex
+		errs []error
+		done int
+		wg   sync.WaitGroup
+		jobs = make(chan string)
+	)
+	throttle := time.NewTicker(max(e.options.Delay, time.Millisecond))
+	defer throttle.Stop()
+
+	for range e.options.Concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for url := range jobs {
+				file, err := e.download(url)
+
+				mu.Lock()
+				if err != nil {
+					errs = append(errs, fmt.Errorf("error at downloading %s: %w", url, err))
+					delete(downloaded, url)
+				} else {
+					downloaded[url] = file
+					done++
+				}
+				// Saved as it goes, so an interrupted run does not download its images again
+				if err == nil && done%imagesManifestEvery == 0 {
+					if err := e.saveManifest(manifest()); err != nil {
+						slog.Error("error at saving images manifest", "cause", err)
+					}
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, url := range urls {
+		<-throttle.C
+		jobs <- url
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, err := range errs {
+		slog.Error("error at downloading image", "cause", err)
+	}
+
+	if err := e.saveManifest(manifest()); err != nil {
+		return err
+	}
+
+	slog.Info("images saved to", "dir", e.options.Dir, "manifest", e.manifestFile(), "failures", len(errs))
+	return nil
+}
+
+// download downloads the image and writes it to the content hash file, unless the same content is already there.
+// The file name is only known once the content is downloaded, so skipping the download is left to the manifest
+func (e *imagesExporter) download(url string) (ImageFile, error) {
+	data, err := e.downloader.DownloadImage(url)
+	if err != nil {
+		return ImageFile{}, err
+	}
+
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	file := ImageFile{
+		URL:    url,
+		Path:   filepath.Join(e.options.Dir, hash+imageExtension(url, data)),
+		SHA256: hash,
+		Size:   len(data),
+	}
+
+	if _, err := os.Stat(file.Path); err == nil {
+		return file, nil
+	}
+
+	// Written to a temporary file first, so an interrupted run never leaves a truncated image behind
+	tmp, err := os.CreateTemp(e.options.Dir, ".download-*")
+	if err != nil {
+		return ImageFile{}, err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return ImageFile{}, err
+	}
+	if err := tmp.Close(); err != nil {
+		return ImageFile{}, err
+	}
+
+	return file, os.Rename(tmp.Name(), file.Path)
+}
+
+// loadManifest returns the previous manifest, without the images whose file is no longer on disk
+func (e *imagesExporter) loadManifest() (ImagesManifest, error) {
+	previous := ImagesManifest{}
+
+	b, err := os.ReadFile(e.manifestFile())
+	if errors.Is(err, os.ErrNotExist) {
+		return previous, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest ImagesManifest
+	if err := json.Unmarshal(b, &manifest); err != nil {
+		return nil, fmt.Errorf("error at unmarshalling images manifest: %w", err)
+	}
+
+	for code, files := range manifest {
+		for _, file := range files {
+			if _, err := os.Stat(file.Path); err == nil {
+				previous[code] = append(previous[code], file)
+			}
+		}
+	}
+	return previous, nil
+}
+
+// saveManifest writes the manifest to a temporary file first, so an interrupted save never leaves a truncated manifest behind
+func (e *imagesExporter) saveManifest(manifest ImagesManifest) error {
+	b, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := e.manifestFile() + ".tmp"
+	if err := os.WriteFile(tmp, b, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, e.manifestFile())
+}
+
+// imageExtension returns the extension of the image URL, or the one of its sniffed content type
+func imageExtension(rawURL string, data []byte) string {
+	if u, err := url.Parse(rawURL); err == nil {
+		if ext := path.Ext(u.Path); ext != "" && len(ext) <= 5 {
+			return ext
+		}
+	}
+
+	if exts, _ := mime.ExtensionsByType(http.DetectContentType(data)); len(exts) > 0 {
+		return exts[0]
+	}
+	return ""
+}
@@ -0,0 +1,153 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"vcrawler/internal/dto"
+)
+
+// httpDownloader downloads the images over HTTP like the store downloaders
+type httpDownloader struct{}
+
+func (httpDownloader) DownloadImage(url string) ([]byte, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+func readManifest(t *testing.T, dir string) ImagesManifest {
+	t.Helper()
+	b, err := os.ReadFile(filepath.Join(dir, imagesManifest))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var manifest ImagesManifest
+	if err := json.Unmarshal(b, &manifest); err != nil {
+		t.Fatal(err)
+	}
+	return manifest
+}
+
+func TestImagesExporter(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		// front.jpg and copy.jpg are the same image
+		if r.URL.Path == "/back.jpg" {
+			w.Write([]byte("back image"))
+			return
+		}
+		w.Write([]byte("front image"))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	exporter := GetImagesExporter(httpDownloader{}, ImagesOptions{Dir: dir, Delay: 1})
+
+	first := []dto.Product{
+		{ArticleCode: "IS8022", Images: []string{srv.URL + "/front.jpg", srv.URL + "/back.jpg"}},
+		{ArticleCode: "IS8023", Images: []string{srv.URL + "/copy.jpg"}},
+	}
+	if err := exporter.Export(first); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	manifest := readManifest(t, dir)
+	if got := requests.Load(); got != 3 {
+		t.Errorf("requests = %d, want 3", got)
+	}
+	front, copied := manifest["IS8022"][0], manifest["IS8023"][0]
+	if front.Path != copied.Path || front.URL == copied.URL {
+		t.Errorf("identical images = %+v and %+v, want the same file", front, copied)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "*.jpg"))
+	if len(files) != 2 {
+		t.Errorf("image files = %v, want 2", files)
+	}
+
+	// The second run only downloads the new image, and keeps IS8023 that it doesn't crawl
+	requests.Store(0)
+	second := []dto.Product{
+		{ArticleCode: "IS8022", Images: []string{srv.URL + "/front.jpg", srv.URL + "/back.jpg", srv.URL + "/side.jpg"}},
+	}
+	if err := exporter.Export(second); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	manifest = readManifest(t, dir)
+	if got := requests.Load(); got != 1 {
+		t.Errorf("requests of the second run = %d, want 1", got)
+	}
+	if len(manifest["IS8022"]) != 3 {
+		t.Errorf("IS8022 images = %+v, want 3", manifest["IS8022"])
+	}
+	if len(manifest["IS8023"]) != 1 || manifest["IS8023"][0] != copied {
+		t.Errorf("IS8023 images = %+v, want the previous %+v", manifest["IS8023"], copied)
+	}
+
+	// An image of the manifest deleted from disk is downloaded again
+	requests.Store(0)
+	if err := os.Remove(manifest["IS8022"][1].Path); err != nil {
+		t.Fatal(err)
+	}
+	if err := exporter.Export(second); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("requests after deleting an image = %d, want 1", got)
+	}
+}
+
+// snapshotDownloader reads the manifest on disk when it is asked for the image after the first save
+type snapshotDownloader struct {
+	dir      string
+	count    int
+	snapshot ImagesManifest
+}
+
+func (d *snapshotDownloader) DownloadImage(url string) ([]byte, error) {
+	d.count++
+	if d.count == imagesManifestEvery+1 {
+		b, err := os.ReadFile(filepath.Join(d.dir, imagesManifest))
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(b, &d.snapshot); err != nil {
+			return nil, err
+		}
+	}
+	return []byte(url), nil
+}
+
+func TestImagesExporterSavesManifestAsItGoes(t *testing.T) {
+	product := dto.Product{ArticleCode: "IS8022"}
+	for i := range imagesManifestEvery + 5 {
+		product.Images = append(product.Images, fmt.Sprintf("https://example.com/%d.jpg", i))
+	}
+
+	dir := t.TempDir()
+	// A single worker, so each download starts after the previous one is recorded
+	downloader := &snapshotDownloader{dir: dir}
+	exporter := GetImagesExporter(downloader, ImagesOptions{Dir: dir, Delay: 1, Concurrency: 1})
+	if err := exporter.Export([]dto.Product{product}); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	if got := len(downloader.snapshot["IS8022"]); got != imagesManifestEvery {
+		t.Errorf("images of the manifest saved during the run = %d, want %d", got, imagesManifestEvery)
+	}
+	if got := len(readManifest(t, dir)["IS8022"]); got != len(product.Images) {
+		t.Errorf("images of the final manifest = %d, want %d", got, len(product.Images))
+	}
+}
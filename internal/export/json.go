@@ -0,0 +1,45 @@
+package export
+
+import (
+	"encoding/json"
+	"log/slog"
+	"os"
+
+	"vcrawler/internal/definition"
+	"vcrawler/internal/dto"
+)
+
+type jsonExporter struct {
+	fileName string
+}
+
+func GetJsonExporter(fileName string) definition.Exporter {
+	return &jsonExporter{fileName: fileName}
+}
+
+func (e *jsonExporter) Outputs() []string {
+	return []string{e.fileName}
+}
+
+func (e *jsonExporter) Export(products []dto.Product) error {
+	// Convert the products data to JSON format
+	productsJSON, err := json.MarshalIndent(products, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Write the JSON data to a file
+	file, err := os.Create(e.fileName)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(productsJSON)
+	if err != nil {
+		return err
+	}
+
+	slog.Info("products data saved to", "file", e.fileName)
+	return nil
+}
@@ -0,0 +1,39 @@
+package export
+
+import (
+	"encoding/json"
+	"log/slog"
+	"os"
+
+	"vcrawler/internal/definition"
+	"vcrawler/internal/dto"
+)
+
+type modelsExporter struct {
+	fileName string
+}
+
+// GetModelsExporter returns an exporter that writes the products grouped by model, with a variant per colorway
+func GetModelsExporter(fileName string) definition.Exporter {
+	return &modelsExporter{fileName: fileName}
+}
+
+func (e *modelsExporter) Outputs() []string {
+	return []string{e.fileName}
+}
+
+func (e *modelsExporter) Export(products []dto.Product) error {
+	models := dto.GroupByModel(products)
+
+	b, err := json.MarshalIndent(models, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(e.fileName, b, 0644); err != nil {
+		return err
+	}
+
+	slog.Info("models data saved to", "file", e.fileName, "models", len(models))
+	return nil
+}
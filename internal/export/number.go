@@ -0,0 +1,17 @@
+package export
+
+import (
+	"strconv"
+	"strings"
+)
+
+// parseNumber returns the number of a formatted value like "19,800" or "86%",
+// nil when the value isn't a number
+func parseNumber(value string) *float64 {
+	value = strings.TrimSuffix(strings.ReplaceAll(strings.TrimSpace(value), ",", ""), "%")
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil
+	}
+	return &n
+}
@@ -0,0 +1,354 @@
+package export
+
+import (
+	"log/slog"
+	"os"
+
+	"vcrawler/internal/definition"
+	"vcrawler/internal/dto"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// parquetProduct is the parquet schema of dto.Product, with typed numbers and nested lists
+type parquetProduct struct {
+	ArticleCode         string               `parquet:"article_code"`
+	ModelCode           string               `parquet:"model_code"`
+	Name                string               `parquet:"name"`
+	URL                 string               `parquet:"url"`
+	ColorName           string               `parquet:"color_name"`
+	BrandName           string               `parquet:"brand_name"`
+	SportName           string               `parquet:"sport_name"`
+	GenderName          string               `parquet:"gender_name"`
+	GenreName           string               `parquet:"genre_name"`
+	ReleaseDate         string               `parquet:"release_date"`
+	ItemStatus          string               `parquet:"item_status"`
+	Limited             bool                 `parquet:"limited"`
+	OptionalLabel       string               `parquet:"optional_label"`
+	FixedPrice          int64                `parquet:"fixed_price"`
+	DiscountPrice       int64                `parquet:"discount_price"`
+	PriceWithTax        int64                `parquet:"price_with_tax"`
+	PriceWithoutTax     int64                `parquet:"price_without_tax"`
+	Currency            string               `parquet:"currency"`
+	TaxRate             float64              `parquet:"tax_rate"`
+	TaxIncluded         bool                 `parquet:"tax_included"`
+	DiscountType        string               `parquet:"discount_type"`
+	Images              []string             `parquet:"images,list"`
+	ImageDetails        []parquetImage       `parquet:"image_details,list"`
+	Breadcrumb          string               `parquet:"breadcrumb"`
+	Breadcrumbs         []parquetBreadcrumb  `parquet:"breadcrumbs,list"`
+	KWs                 string               `parquet:"kws"`
+	Categories          []parquetCategory    `parquet:"categories,list"`
+	AvailableSize       string               `parquet:"available_size"`
+	SenseOfTheSize      *float64             `parquet:"sense_of_the_size,optional"`
+	Coordinates         []parquetCoordinate  `parquet:"coordinates,list"`
+	DescriptionTitle    string               `parquet:"description_title"`
+	DescriptionGeneral  string               `parquet:"description_general"`
+	DescriptionBreads   []string             `parquet:"description_breads,list"`
+	Skus                []parquetSku         `parquet:"skus,list"`
+	SizeCharts          []parquetSizeChart   `parquet:"size_charts,list"`
+	Technologies        []parquetTechnology  `parquet:"technologies,list"`
+	ReviewCount         *float64             `parquet:"review_count,optional"`
+	Reviews             []parquetReview      `parquet:"reviews,list"`
+	Rating              *float64             `parquet:"rating,optional"`
+	RecommendedRate     *float64             `parquet:"recommended_rate,optional"`
+	RatingSenses        []parquetRatingSense `parquet:"rating_senses,list"`
+	RatingMissingReason string               `parquet:"rating_missing_reason,optional"`
+	AverageRating       float64              `parquet:"average_rating"`
+	RecommendedPercent  int32                `parquet:"recommended_percent"`
+	RatingHistogram     []parquetRatingCount `parquet:"rating_histogram,list"`
+}
+
+type parquetImage struct {
+	Large   string `parquet:"large"`
+	Medium  string `parquet:"medium"`
+	Small   string `parquet:"small"`
+	Caption string `parquet:"caption"`
+	View    string `parquet:"view"`
+	Order   int32  `parquet:"order"`
+	Listing string `parquet:"listing"`
+}
+
+type parquetBreadcrumb struct {
+	Label     string `parquet:"label"`
+	SearchURL string `parquet:"search_url"`
+}
+
+type parquetCategory struct {
+	Label string `parquet:"label"`
+	Link  string `parquet:"link"`
+}
+
+type parquetTechnology struct {
+	Name string `parquet:"name"`
+	Desc string `parquet:"desc"`
+}
+
+type parquetCoordinate struct {
+	ProductName            string `parquet:"product_name"`
+	ProductURL             string `parquet:"product_url"`
+	ProductImage           string `parquet:"product_image"`
+	ProductPriceWithTax    int64  `parquet:"product_price_with_tax"`
+	ProductPriceWithoutTax int64  `parquet:"product_price_without_tax"`
+	ProductDiscountType    string `parquet:"product_discount_type"`
+}
+
+type parquetSku struct {
+	SizeName      string      `parquet:"size_name"`
+	CanonicalSize parquetSize `parquet:"canonical_size"`
+	Code          string      `parquet:"code"`
+	IsStockEc     bool        `parquet:"is_stock"`
+	IsStockStore  bool        `parquet:"is_stock_store"`
+	IsSoldOut     bool        `parquet:"is_sold_out"`
+	StockMessage  string      `parquet:"stock_message"`
+	StockIcon     string      `parquet:"stock_icon"`
+	CanAddToCart  bool        `parquet:"can_add_to_cart"`
+	CartLabel     string      `parquet:"add_to_cart_label"`
+}
+
+type parquetSize struct {
+	Code    string `parquet:"code"`
+	System  string `parquet:"system"`
+	Variant string `parquet:"variant"`
+}
+
+type parquetSizeChart struct {
+	Section       string               `parquet:"section"`
+	Size          string               `parquet:"size"`
+	CanonicalSize parquetSize          `parquet:"canonical_size"`
+	ActualSize    bool                 `parquet:"actual_size"`
+	Exact         bool                 `parquet:"exact"`
+	Measurements  []parquetMeasurement `parquet:"measurements,list"`
+}
+
+type parquetMeasurement struct {
+	Type  string   `parquet:"type"`
+	Value string   `parquet:"value"`
+	Key   string   `parquet:"key"`
+	Label string   `parquet:"label"`
+	Min   *float64 `parquet:"min,optional"`
+	Max   *float64 `parquet:"max,optional"`
+	Unit  string   `parquet:"unit"`
+}
+
+type parquetReview struct {
+	AuthorName    string                   `parquet:"author_name"`
+	DatePublished string                   `parquet:"date_published"`
+	Title         string                   `parquet:"title"`
+	Body          string                   `parquet:"body"`
+	BestRating    *float64                 `parquet:"best_rating,optional"`
+	RatingValue   *float64                 `parquet:"rating_value,optional"`
+	Attributes    []parquetReviewAttribute `parquet:"attributes,list"`
+	HelpfulYes    int32                    `parquet:"helpful_yes"`
+	HelpfulNo     int32                    `parquet:"helpful_no"`
+	Photos        []string                 `parquet:"photos,list"`
+}
+
+type parquetReviewAttribute struct {
+	Label string `parquet:"label"`
+	Value string `parquet:"value"`
+}
+
+type parquetRatingSense struct {
+	Type     string  `parquet:"type"`
+	Value    string  `parquet:"value"`
+	Position float64 `parquet:"position"`
+	MinLabel string  `parquet:"min_label"`
+	MaxLabel string  `parquet:"max_label"`
+}
+
+type parquetRatingCount struct {
+	Stars int32 `parquet:"stars"`
+	Count int32 `parquet:"count"`
+}
+
+type parquetExporter struct {
+	fileName string
+}
+
+func GetParquetExporter(fileName string) definition.Exporter {
+	return &parquetExporter{fileName: fileName}
+}
+
+func (e *parquetExporter) Outputs() []string {
+	return []string{e.fileName}
+}
+
+func (e *parquetExporter) Export(products []dto.Product) error {
+	rows := make([]parquetProduct, 0, len(products))
+	for _, product := range products {
+		rows = append(rows, toParquetProduct(product))
+	}
+
+	file, err := os.Create(e.fileName)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w := parquet.NewGenericWriter[parquetProduct](file, parquet.Compression(&parquet.Snappy))
+	if _, err := w.Write(rows); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	slog.Info("products data saved to", "file", e.fileName)
+	return nil
+}
+
+func toParquetProduct(p dto.Product) parquetProduct {
+	row := parquetProduct{
+		ArticleCode:         p.ArticleCode,
+		ModelCode:           p.ModelCode,
+		Name:                p.Name,
+		URL:                 p.URL,
+		ColorName:           p.ColorName,
+		BrandName:           p.BrandName,
+		SportName:           p.SportName,
+		GenderName:          p.GenderName,
+		GenreName:           p.GenreName,
+		ReleaseDate:         p.ReleaseDate,
+		ItemStatus:          p.ItemStatus,
+		Limited:             p.Limited,
+		OptionalLabel:       p.OptionalLabel,
+		FixedPrice:          int64(p.FixedPrice),
+		DiscountPrice:       int64(p.DiscountPrice),
+		PriceWithTax:        int64(p.Price.WithTax),
+		PriceWithoutTax:     int64(p.Price.WithoutTax),
+		Currency:            p.Price.Currency,
+		TaxRate:             p.Price.TaxRate,
+		TaxIncluded:         p.Price.TaxIncluded,
+		DiscountType:        p.Price.DiscountType,
+		Images:              p.Images,
+		Breadcrumb:          p.Breadcrumb,
+		KWs:                 p.KWs,
+		AvailableSize:       p.SizeChoice.AvailableSize,
+		SenseOfTheSize:      parseNumber(p.SizeChoice.SenseOfTheSize),
+		DescriptionTitle:    p.Description.Title,
+		DescriptionGeneral:  p.Description.General,
+		DescriptionBreads:   p.Description.Breads,
+		ReviewCount:         parseNumber(p.ReviewCount),
+		Rating:              parseNumber(p.Rating),
+		RecommendedRate:     parseNumber(p.RecommendedRate),
+		RatingMissingReason: string(p.RatingMissingReason),
+		AverageRating:       p.AverageRating,
+		RecommendedPercent:  int32(p.RecommendedPercent),
+	}
+
+	for _, image := range p.ImageDetails {
+		row.ImageDetails = append(row.ImageDetails, parquetImage{
+			Large:   image.Large,
+			Medium:  image.Medium,
+			Small:   image.Small,
+			Caption: image.Caption,
+			View:    image.View,
+			Order:   int32(image.Order),
+			Listing: image.Listing,
+		})
+	}
+
+	for _, bc := range p.Breadcrumbs {
+		row.Breadcrumbs = append(row.Breadcrumbs, parquetBreadcrumb{Label: bc.Label, SearchURL: bc.SearchURL})
+	}
+
+	for _, category := range p.Categories {
+		row.Categories = append(row.Categories, parquetCategory{Label: category.Label, Link: category.Link})
+	}
+
+	for _, tech := range p.Technologies {
+		row.Technologies = append(row.Technologies, parquetTechnology{Name: tech.Name, Desc: tech.Desc})
+	}
+
+	for _, coordinate := range p.Coordinates {
+		row.Coordinates = append(row.Coordinates, parquetCoordinate{
+			ProductName:            coordinate.ProductName,
+			ProductURL:             coordinate.ProductURL,
+			ProductImage:           coordinate.ProductImage,
+			ProductPriceWithTax:    int64(coordinate.ProductPrice.WithTax),
+			ProductPriceWithoutTax: int64(coordinate.ProductPrice.WithoutTax),
+			ProductDiscountType:    coordinate.ProductPrice.DiscountType,
+		})
+	}
+
+	for _, sku := range p.Skus {
+		row.Skus = append(row.Skus, parquetSku{
+			SizeName:      sku.SizeName,
+			CanonicalSize: toParquetSize(sku.CanonicalSize),
+			Code:          sku.Code,
+			IsStockEc:     sku.Status.IsStockEc,
+			IsStockStore:  sku.Status.IsStockStore,
+			IsSoldOut:     sku.Status.IsSoldOut,
+			StockMessage:  sku.StockMessage,
+			StockIcon:     sku.StockIcon,
+			CanAddToCart:  sku.CanAddToCart,
+			CartLabel:     sku.AddToCartLabel,
+		})
+	}
+
+	for _, sizeChart := range p.SizeCharts {
+		sc := parquetSizeChart{
+			Section:       sizeChart.Section,
+			Size:          sizeChart.Size,
+			CanonicalSize: toParquetSize(sizeChart.CanonicalSize),
+			ActualSize:    sizeChart.ActualSize,
+			Exact:         sizeChart.Exact,
+		}
+		for _, measurement := range sizeChart.Measurements {
+			m := parquetMeasurement{
+				Type:  measurement.Type,
+				Value: measurement.Value,
+				Key:   measurement.Key,
+				Label: measurement.Label,
+				Unit:  measurement.Unit,
+			}
+			if measurement.Raw == "" {
+				m.Min, m.Max = &measurement.Min, &measurement.Max
+			}
+			sc.Measurements = append(sc.Measurements, m)
+		}
+		row.SizeCharts = append(row.SizeCharts, sc)
+	}
+
+	for _, review := range p.Reviews {
+		r := parquetReview{
+			AuthorName:    review.AuthorName,
+			DatePublished: review.DatePublished,
+			Title:         review.Title,
+			Body:          review.Body,
+			BestRating:    parseNumber(review.BestRating),
+			RatingValue:   parseNumber(review.RatingValue),
+			HelpfulYes:    int32(review.HelpfulYes),
+			HelpfulNo:     int32(review.HelpfulNo),
+			Photos:        review.Photos,
+		}
+		for _, attribute := range review.Attributes {
+			r.Attributes = append(r.Attributes, parquetReviewAttribute{Label: attribute.Label, Value: attribute.Value})
+		}
+		row.Reviews = append(row.Reviews, r)
+	}
+
+	for _, ratingSense := range p.RatingSenses {
+		row.RatingSenses = append(row.RatingSenses, parquetRatingSense{
+			Type:     ratingSense.Type,
+			Value:    ratingSense.Value,
+			Position: ratingSense.Position,
+			MinLabel: ratingSense.MinLabel,
+			MaxLabel: ratingSense.MaxLabel,
+		})
+	}
+
+	for _, ratingCount := range p.RatingHistogram {
+		row.RatingHistogram = append(row.RatingHistogram, parquetRatingCount{
+			Stars: int32(ratingCount.Stars),
+			Count: int32(ratingCount.Count),
+		})
+	}
+
+	return row
+}
+
+func toParquetSize(size dto.Size) parquetSize {
+	return parquetSize{Code: size.Code, System: string(size.System), Variant: size.Variant}
+}
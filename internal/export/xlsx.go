@@ -0,0 +1,362 @@
+package export
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"vcrawler/internal/definition"
+	"vcrawler/internal/dto"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	xlsxProductsSheet   = "Products"
+	xlsxSkusSheet       = "SKUs"
+	xlsxSizeChartsSheet = "SizeCharts"
+	xlsxReviewsSheet    = "Reviews"
+	xlsxImagesSheet     = "Images"
+)
+
+// xlsxColumn is a sheet column and the way to extract its cell value from a row
+type xlsxColumn[T any] struct {
+	header string
+	width  float64
+	style  xlsxStyle
+	value  func(row T) any
+}
+
+type xlsxStyle int
+
+const (
+	xlsxText xlsxStyle = iota
+	xlsxPrice
+	xlsxLink
+)
+
+type xlsxSkuRow struct {
+	ArticleCode string
+	Sku         dto.Sku
+}
+
+type xlsxSizeChartRow struct {
+	ArticleCode string
+	SizeChart   dto.SizeChart
+	Measurement dto.Measurement
+}
+
+type xlsxReviewRow struct {
+	ArticleCode string
+	Review      dto.Review
+}
+
+type xlsxImageRow struct {
+	ArticleCode string
+	Image       dto.Image
+}
+
+type xlsxExporter struct {
+	fileName string
+}
+
+func GetXlsxExporter(fileName string) definition.Exporter {
+	return &xlsxExporter{fileName: fileName}
+}
+
+func (e *xlsxExporter) Outputs() []string {
+	return []string{e.fileName}
+}
+
+func (e *xlsxExporter) Export(products []dto.Product) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	styles, err := newXlsxStyles(f)
+	if err != nil {
+		return err
+	}
+
+	var (
+		skus       []xlsxSkuRow
+		sizeCharts []xlsxSizeChartRow
+		reviews    []xlsxReviewRow
+		images     []xlsxImageRow
+	)
+	for _, product := range products {
+		for _, sku := range product.Skus {
+			skus = append(skus, xlsxSkuRow{ArticleCode: product.ArticleCode, Sku: sku})
+		}
+		for _, sizeChart := range product.SizeCharts {
+			for _, measurement := range sizeChart.Measurements {
+				sizeCharts = append(sizeCharts, xlsxSizeChartRow{ArticleCode: product.ArticleCode, SizeChart: sizeChart, Measurement: measurement})
+			}
+		}
+		for _, review := range product.Reviews {
+			reviews = append(reviews, xlsxReviewRow{ArticleCode: product.ArticleCode, Review: review})
+		}
+		for _, image := range product.ImageDetails {
+			images = append(images, xlsxImageRow{ArticleCode: product.ArticleCode, Image: image})
+		}
+	}
+
+	// The new file comes with a default sheet, rename it to the first sheet
+	if err := f.SetSheetName(f.GetSheetName(0), xlsxProductsSheet); err != nil {
+		return err
+	}
+	if err := writeXlsxSheet(f, styles, xlsxProductsSheet, xlsxProductColumns, products); err != nil {
+		return err
+	}
+	if err := writeXlsxSheet(f, styles, xlsxSkusSheet, xlsxSkuColumns, skus); err != nil {
+		return err
+	}
+	if err := writeXlsxSheet(f, styles, xlsxSizeChartsSheet, xlsxSizeChartColumns, sizeCharts); err != nil {
+		return err
+	}
+	if err := writeXlsxSheet(f, styles, xlsxReviewsSheet, xlsxReviewColumns, reviews); err != nil {
+		return err
+	}
+	if err := writeXlsxSheet(f, styles, xlsxImagesSheet, xlsxImageColumns, images); err != nil {
+		return err
+	}
+
+	if err := f.SaveAs(e.fileName); err != nil {
+		return err
+	}
+
+	slog.Info("products data saved to", "file", e.fileName)
+	return nil
+}
+
+type xlsxStyles struct {
+	header int
+	cells  map[xlsxStyle]int
+}
+
+func newXlsxStyles(f *excelize.File) (xlsxStyles, error) {
+	var (
+		styles xlsxStyles
+		err    error
+	)
+
+	styles.header, err = f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true},
+		Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"D9D9D9"}},
+	})
+	if err != nil {
+		return styles, err
+	}
+
+	// Built-in number format 3 is #,##0
+	price, err := f.NewStyle(&excelize.Style{NumFmt: 3})
+	if err != nil {
+		return styles, err
+	}
+
+	link, err := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Color: "0563C1", Underline: "single"},
+	})
+	if err != nil {
+		return styles, err
+	}
+
+	styles.cells = map[xlsxStyle]int{xlsxPrice: price, xlsxLink: link}
+	return styles, nil
+}
+
+// writeXlsxSheet writes the rows to the sheet, with a frozen header row and an autofilter
+func writeXlsxSheet[T any](f *excelize.File, styles xlsxStyles, sheet string, columns []xlsxColumn[T], rows []T) error {
+	if _, err := f.NewSheet(sheet); err != nil {
+		return err
+	}
+
+	header := make([]any, 0, len(columns))
+	for _, column := range columns {
+		header = append(header, column.header)
+	}
+	if err := f.SetSheetRow(sheet, "A1", &header); err != nil {
+		return err
+	}
+
+	lastCol, err := excelize.ColumnNumberToName(len(columns))
+	if err != nil {
+		return err
+	}
+	if err := f.SetCellStyle(sheet, "A1", lastCol+"1", styles.header); err != nil {
+		return err
+	}
+
+	for ri, row := range rows {
+		values := make([]any, 0, len(columns))
+		for _, column := range columns {
+			values = append(values, column.value(row))
+		}
+
+		rowNumber := ri + 2
+		if err := f.SetSheetRow(sheet, fmt.Sprintf("A%d", rowNumber), &values); err != nil {
+			return err
+		}
+
+		for ci, column := range columns {
+			if column.style == xlsxText {
+				continue
+			}
+
+			cell, err := excelize.CoordinatesToCellName(ci+1, rowNumber)
+			if err != nil {
+				return err
+			}
+			if err := f.SetCellStyle(sheet, cell, cell, styles.cells[column.style]); err != nil {
+				return err
+			}
+
+			if url, ok := values[ci].(string); ok && column.style == xlsxLink && url != "" {
+				if err := f.SetCellHyperLink(sheet, cell, url, "External"); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	for ci, column := range columns {
+		if column.width == 0 {
+			continue
+		}
+
+		col, err := excelize.ColumnNumberToName(ci + 1)
+		if err != nil {
+			return err
+		}
+		if err := f.SetColWidth(sheet, col, col, column.width); err != nil {
+			return err
+		}
+	}
+
+	if err := f.SetPanes(sheet, &excelize.Panes{
+		Freeze:      true,
+		YSplit:      1,
+		TopLeftCell: "A2",
+		ActivePane:  "bottomLeft",
+	}); err != nil {
+		return err
+	}
+
+	return f.AutoFilter(sheet, fmt.Sprintf("A1:%s%d", lastCol, len(rows)+1), nil)
+}
+
+// xlsxNumber returns the number of a formatted value like "19,800", or the value itself when it isn't a number
+func xlsxNumber(value string) any {
+	if n := parseNumber(value); n != nil {
+		return *n
+	}
+	return value
+}
+
+// xlsxOptional returns the number, or an empty cell when it isn't set
+func xlsxOptional(n float64, ok bool) any {
+	if !ok {
+		return ""
+	}
+	return n
+}
+
+var xlsxProductColumns = []xlsxColumn[dto.Product]{
+	{"article_code", 12, xlsxText, func(p dto.Product) any { return p.ArticleCode }},
+	{"model_code", 12, xlsxText, func(p dto.Product) any { return p.ModelCode }},
+	{"name", 50, xlsxText, func(p dto.Product) any { return p.Name }},
+	{"url", 40, xlsxLink, func(p dto.Product) any { return p.URL }},
+	{"color_name", 24, xlsxText, func(p dto.Product) any { return p.ColorName }},
+	{"brand_name", 14, xlsxText, func(p dto.Product) any { return p.BrandName }},
+	{"sport_name", 14, xlsxText, func(p dto.Product) any { return p.SportName }},
+	{"gender_name", 10, xlsxText, func(p dto.Product) any { return p.GenderName }},
+	{"genre_name", 14, xlsxText, func(p dto.Product) any { return p.GenreName }},
+	{"release_date", 20, xlsxText, func(p dto.Product) any { return p.ReleaseDate }},
+	{"item_status", 12, xlsxText, func(p dto.Product) any { return p.ItemStatus }},
+	{"limited", 8, xlsxText, func(p dto.Product) any { return p.Limited }},
+	{"optional_label", 16, xlsxText, func(p dto.Product) any { return p.OptionalLabel }},
+	{"fixed_price", 14, xlsxPrice, func(p dto.Product) any { return p.FixedPrice }},
+	{"discount_price", 14, xlsxPrice, func(p dto.Product) any { return p.DiscountPrice }},
+	{"price_with_tax", 14, xlsxPrice, func(p dto.Product) any { return p.Price.WithTax }},
+	{"price_without_tax", 14, xlsxPrice, func(p dto.Product) any { return p.Price.WithoutTax }},
+	{"currency", 10, xlsxText, func(p dto.Product) any { return p.Price.Currency }},
+	{"tax_rate", 10, xlsxText, func(p dto.Product) any { return p.Price.TaxRate }},
+	{"discount_type", 12, xlsxText, func(p dto.Product) any { return p.Price.DiscountType }},
+	{"breadcrumb", 50, xlsxText, func(p dto.Product) any { return p.Breadcrumb }},
+	{"kws", 40, xlsxText, func(p dto.Product) any { return p.KWs }},
+	{"available_size", 20, xlsxText, func(p dto.Product) any { return p.SizeChoice.AvailableSize }},
+	{"sense_of_the_size", 12, xlsxText, func(p dto.Product) any { return xlsxNumber(p.SizeChoice.SenseOfTheSize) }},
+	{"description_title", 40, xlsxText, func(p dto.Product) any { return p.Description.Title }},
+	{"general_description", 60, xlsxText, func(p dto.Product) any { return stripBreaks(p.Description.General) }},
+	{"general_itemization_description", 60, xlsxText, func(p dto.Product) any { return strings.Join(p.Description.Breads, "\n") }},
+	{"review_count", 12, xlsxText, func(p dto.Product) any { return xlsxNumber(p.ReviewCount) }},
+	{"rating", 10, xlsxText, func(p dto.Product) any { return xlsxNumber(p.Rating) }},
+	{"recommended_rate", 12, xlsxText, func(p dto.Product) any { return p.RecommendedRate }},
+	{"average_rating", 12, xlsxText, func(p dto.Product) any { return p.AverageRating }},
+	{"recommended_percent", 12, xlsxText, func(p dto.Product) any { return p.RecommendedPercent }},
+	{"rating_missing_reason", 18, xlsxText, func(p dto.Product) any { return string(p.RatingMissingReason) }},
+	{"images", 40, xlsxText, func(p dto.Product) any { return strings.Join(p.Images, "\n") }},
+}
+
+var xlsxSkuColumns = []xlsxColumn[xlsxSkuRow]{
+	{"article_code", 12, xlsxText, func(r xlsxSkuRow) any { return r.ArticleCode }},
+	{"size_name", 12, xlsxText, func(r xlsxSkuRow) any { return r.Sku.SizeName }},
+	{"code", 16, xlsxText, func(r xlsxSkuRow) any { return r.Sku.Code }},
+	{"canonical_size", 14, xlsxText, func(r xlsxSkuRow) any { return r.Sku.CanonicalSize.Code }},
+	{"size_system", 14, xlsxText, func(r xlsxSkuRow) any { return string(r.Sku.CanonicalSize.System) }},
+	{"size_variant", 14, xlsxText, func(r xlsxSkuRow) any { return r.Sku.CanonicalSize.Variant }},
+	{"is_stock", 10, xlsxText, func(r xlsxSkuRow) any { return r.Sku.Status.IsStockEc }},
+	{"is_stock_store", 14, xlsxText, func(r xlsxSkuRow) any { return r.Sku.Status.IsStockStore }},
+	{"is_sold_out", 12, xlsxText, func(r xlsxSkuRow) any { return r.Sku.Status.IsSoldOut }},
+	{"stock_message", 30, xlsxText, func(r xlsxSkuRow) any { return r.Sku.StockMessage }},
+	{"can_add_to_cart", 14, xlsxText, func(r xlsxSkuRow) any { return r.Sku.CanAddToCart }},
+	{"add_to_cart_label", 20, xlsxText, func(r xlsxSkuRow) any { return r.Sku.AddToCartLabel }},
+}
+
+var xlsxSizeChartColumns = []xlsxColumn[xlsxSizeChartRow]{
+	{"article_code", 12, xlsxText, func(r xlsxSizeChartRow) any { return r.ArticleCode }},
+	{"section", 8, xlsxText, func(r xlsxSizeChartRow) any { return r.SizeChart.Section }},
+	{"size", 10, xlsxText, func(r xlsxSizeChartRow) any { return r.SizeChart.Size }},
+	{"canonical_size", 14, xlsxText, func(r xlsxSizeChartRow) any { return r.SizeChart.CanonicalSize.Code }},
+	{"size_system", 14, xlsxText, func(r xlsxSizeChartRow) any { return string(r.SizeChart.CanonicalSize.System) }},
+	{"size_variant", 14, xlsxText, func(r xlsxSizeChartRow) any { return r.SizeChart.CanonicalSize.Variant }},
+	{"actual_size", 12, xlsxText, func(r xlsxSizeChartRow) any { return r.SizeChart.ActualSize }},
+	{"exact", 8, xlsxText, func(r xlsxSizeChartRow) any { return r.SizeChart.Exact }},
+	{"type", 20, xlsxText, func(r xlsxSizeChartRow) any { return r.Measurement.Type }},
+	{"value", 16, xlsxText, func(r xlsxSizeChartRow) any { return r.Measurement.Value }},
+	{"key", 20, xlsxText, func(r xlsxSizeChartRow) any { return r.Measurement.Key }},
+	{"label", 20, xlsxText, func(r xlsxSizeChartRow) any { return r.Measurement.Label }},
+	{"min", 8, xlsxText, func(r xlsxSizeChartRow) any { return xlsxOptional(r.Measurement.Min, r.Measurement.Raw == "") }},
+	{"max", 8, xlsxText, func(r xlsxSizeChartRow) any { return xlsxOptional(r.Measurement.Max, r.Measurement.Raw == "") }},
+	{"unit", 8, xlsxText, func(r xlsxSizeChartRow) any { return r.Measurement.Unit }},
+}
+
+var xlsxReviewColumns = []xlsxColumn[xlsxReviewRow]{
+	{"article_code", 12, xlsxText, func(r xlsxReviewRow) any { return r.ArticleCode }},
+	{"author_name", 20, xlsxText, func(r xlsxReviewRow) any { return r.Review.AuthorName }},
+	{"date_published", 14, xlsxText, func(r xlsxReviewRow) any { return r.Review.DatePublished }},
+	{"rating_value", 12, xlsxText, func(r xlsxReviewRow) any { return xlsxNumber(r.Review.RatingValue) }},
+	{"best_rating", 12, xlsxText, func(r xlsxReviewRow) any { return xlsxNumber(r.Review.BestRating) }},
+	{"title", 30, xlsxText, func(r xlsxReviewRow) any { return r.Review.Title }},
+	{"body", 80, xlsxText, func(r xlsxReviewRow) any { return r.Review.Body }},
+	{"attributes", 40, xlsxText, func(r xlsxReviewRow) any {
+		var attributes []string
+		for _, attribute := range r.Review.Attributes {
+			attributes = append(attributes, attribute.Label+": "+attribute.Value)
+		}
+		return strings.Join(attributes, "\n")
+	}},
+	{"helpful_yes", 12, xlsxText, func(r xlsxReviewRow) any { return r.Review.HelpfulYes }},
+	{"helpful_no", 12, xlsxText, func(r xlsxReviewRow) any { return r.Review.HelpfulNo }},
+	{"photos", 40, xlsxText, func(r xlsxReviewRow) any { return strings.Join(r.Review.Photos, "\n") }},
+}
+
+var xlsxImageColumns = []xlsxColumn[xlsxImageRow]{
+	{"article_code", 12, xlsxText, func(r xlsxImageRow) any { return r.ArticleCode }},
+	{"order", 8, xlsxText, func(r xlsxImageRow) any { return r.Image.Order }},
+	{"view", 30, xlsxText, func(r xlsxImageRow) any { return r.Image.View }},
+	{"listing", 10, xlsxText, func(r xlsxImageRow) any { return r.Image.Listing }},
+	{"caption", 30, xlsxText, func(r xlsxImageRow) any { return r.Image.Caption }},
+	{"large", 40, xlsxLink, func(r xlsxImageRow) any { return r.Image.Large }},
+	{"medium", 40, xlsxLink, func(r xlsxImageRow) any { return r.Image.Medium }},
+	{"small", 40, xlsxLink, func(r xlsxImageRow) any { return r.Image.Small }},
+}
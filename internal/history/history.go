@@ -0,0 +1,117 @@
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"vcrawler/internal/definition"
+	"vcrawler/internal/dto"
+)
+
+// DefaultDir is the directory of the price history, relative to the working directory
+const DefaultDir = "history"
+
+// Observation is the price and the size availability of an article seen in a run
+type Observation struct {
+	ObservedAt time.Time        `json:"observed_at"`
+	Price      dto.Price        `json:"price"`
+	Skus       []SkuObservation `json:"skus"`
+}
+
+// SkuObservation is a size of the article seen in a run, sold at the article price
+type SkuObservation struct {
+	SizeName string        `json:"size_name"`
+	Code     string        `json:"code"`
+	Status   dto.SkuStatus `json:"status"`
+}
+
+// recorder appends the observations of each run to a JSON lines file per article code
+type recorder struct {
+	dir string
+}
+
+// GetRecorder returns an exporter that keeps the price history of the products in dir
+func GetRecorder(dir string) definition.Exporter {
+	return &recorder{dir: dir}
+}
+
+// Outputs is empty, the history is kept locally across runs and isn't a run output
+func (r *recorder) Outputs() []string {
+	return nil
+}
+
+func (r *recorder) Export(products []dto.Product) error {
+	if err := os.MkdirAll(r.dir, 0755); err != nil {
+		return err
+	}
+
+	observedAt := time.Now().UTC()
+	for _, product := range products {
+		if product.ArticleCode == "" {
+			continue
+		}
+
+		observation := Observation{ObservedAt: observedAt, Price: product.Price}
+		for _, sku := range product.Skus {
+			observation.Skus = append(observation.Skus, SkuObservation{SizeName: sku.SizeName, Code: sku.Code, Status: sku.Status})
+		}
+
+		if err := r.append(product.ArticleCode, observation); err != nil {
+			return err
+		}
+	}
+
+	slog.Info("price history saved to", "dir", r.dir, "articles", len(products))
+	return nil
+}
+
+func (r *recorder) append(articleCode string, observation Observation) error {
+	b, err := json.Marshal(observation)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(fileName(r.dir, articleCode), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(b, '\n'))
+	return err
+}
+
+// Load returns the observations of an article in the order they were recorded
+func Load(dir, articleCode string) ([]Observation, error) {
+	file, err := os.Open(fileName(dir, articleCode))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("no price history of %s in %s", articleCode, dir)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var observations []Observation
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var observation Observation
+		if err := json.Unmarshal(scanner.Bytes(), &observation); err != nil {
+			return nil, fmt.Errorf("error at unmarshalling price history of %s: %w", articleCode, err)
+		}
+		observations = append(observations, observation)
+	}
+
+	return observations, scanner.Err()
+}
+
+func fileName(dir, articleCode string) string {
+	return filepath.Join(dir, filepath.Base(articleCode)+".jsonl")
+}
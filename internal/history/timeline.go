@@ -0,0 +1,108 @@
+package history
+
+import (
+	"maps"
+	"slices"
+	"time"
+
+	"vcrawler/internal/dto"
+)
+
+// DiscountTypeProper is the discount type of a product sold at its regular price
+const DiscountTypeProper = "proper"
+
+// PricePoint is a price seen in consecutive observations, from its first to its last observation
+type PricePoint struct {
+	From  time.Time
+	To    time.Time
+	Price dto.Price
+}
+
+// DiscountPeriod is a period where the article wasn't sold at its regular price
+type DiscountPeriod struct {
+	From         time.Time
+	To           time.Time
+	DiscountType string
+	Ongoing      bool // The discount was still seen in the last observation
+}
+
+// SkuChange is a change of the availability of a size
+type SkuChange struct {
+	At       time.Time
+	SizeName string
+	Status   dto.SkuStatus
+	Removed  bool // The size isn't listed anymore
+}
+
+// Timeline returns the price points of the observations, merging the consecutive observations with the same price
+func Timeline(observations []Observation) []PricePoint {
+	var points []PricePoint
+	for _, o := range observations {
+		if n := len(points); n > 0 && samePrice(points[n-1].Price, o.Price) {
+			points[n-1].To = o.ObservedAt
+			continue
+		}
+		points = append(points, PricePoint{From: o.ObservedAt, To: o.ObservedAt, Price: o.Price})
+	}
+	return points
+}
+
+// DiscountPeriods returns the periods where the discount type of the observations wasn't proper
+func DiscountPeriods(observations []Observation) []DiscountPeriod {
+	var (
+		periods []DiscountPeriod
+		current *DiscountPeriod
+	)
+	for _, o := range observations {
+		discountType := o.Price.DiscountType
+		if discountType == DiscountTypeProper || discountType == "" {
+			current = nil
+			continue
+		}
+
+		if current != nil && current.DiscountType == discountType {
+			current.To = o.ObservedAt
+			continue
+		}
+
+		periods = append(periods, DiscountPeriod{From: o.ObservedAt, To: o.ObservedAt, DiscountType: discountType})
+		current = &periods[len(periods)-1]
+	}
+
+	if current != nil {
+		current.Ongoing = true
+	}
+	return periods
+}
+
+// SkuChanges returns the availability of each size when first seen and every time it changed
+func SkuChanges(observations []Observation) []SkuChange {
+	var (
+		changes []SkuChange
+		last    = map[string]dto.SkuStatus{}
+	)
+	for _, o := range observations {
+		seen := map[string]bool{}
+		for _, sku := range o.Skus {
+			seen[sku.SizeName] = true
+			if status, ok := last[sku.SizeName]; ok && status == sku.Status {
+				continue
+			}
+			last[sku.SizeName] = sku.Status
+			changes = append(changes, SkuChange{At: o.ObservedAt, SizeName: sku.SizeName, Status: sku.Status})
+		}
+
+		for _, size := range slices.Sorted(maps.Keys(last)) {
+			if !seen[size] {
+				delete(last, size)
+				changes = append(changes, SkuChange{At: o.ObservedAt, SizeName: size, Removed: true})
+			}
+		}
+	}
+	return changes
+}
+
+// samePrice compares the amounts and the discount type, the display strings may differ between schema versions
+func samePrice(a, b dto.Price) bool {
+	return a.WithTax == b.WithTax && a.WithoutTax == b.WithoutTax && a.DiscountType == b.DiscountType
+}
@@ -0,0 +1,163 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"vcrawler/internal/dto"
+)
+
+// testdata/IS8022.jsonl starts with a pre-v2 observation, whose prices are display strings
+
+func day(d int) time.Time {
+	return time.Date(2024, 8, d, 1, 0, 0, 0, time.UTC)
+}
+
+func loadFixture(t *testing.T) []Observation {
+	t.Helper()
+	observations, err := Load("testdata", "IS8022")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(observations) != 7 {
+		t.Fatalf("Load() = %d observations, want 7", len(observations))
+	}
+	return observations
+}
+
+func TestLoadLegacyPrice(t *testing.T) {
+	price := loadFixture(t)[0].Price
+	want := dto.Price{
+		WithTax: 19800, WithoutTax: 18000, Currency: "JPY", TaxRate: 0.1, TaxIncluded: true,
+		DisplayWithTax: "19,800", DisplayWithoutTax: "18,000", DiscountType: DiscountTypeProper,
+	}
+	if price != want {
+		t.Errorf("legacy price = %+v, want %+v", price, want)
+	}
+}
+
+func TestTimeline(t *testing.T) {
+	type point struct {
+		From, To     time.Time
+		WithTax      int
+		DiscountType string
+	}
+	want := []point{
+		// The legacy price is the same as the v2 price of the next run
+		{day(1), day(2), 19800, DiscountTypeProper},
+		{day(3), day(4), 13860, "sale"},
+		{day(5), day(5), 9900, "outlet"},
+		{day(6), day(6), 19800, DiscountTypeProper},
+		{day(7), day(7), 15840, "sale"},
+	}
+
+	var got []point
+	for _, p := range Timeline(loadFixture(t)) {
+		got = append(got, point{p.From, p.To, p.Price.WithTax, p.Price.DiscountType})
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Timeline() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestDiscountPeriods(t *testing.T) {
+	observation := func(d int, discountType string) Observation {
+		return Observation{ObservedAt: day(d), Price: dto.Price{DiscountType: discountType}}
+	}
+
+	tests := []struct {
+		name         string
+		observations []Observation
+		want         []DiscountPeriod
+	}{
+		{
+			name:         "fixture: back-to-back periods and a period open at the last entry",
+			observations: loadFixture(t),
+			want: []DiscountPeriod{
+				{From: day(3), To: day(4), DiscountType: "sale"},
+				{From: day(5), To: day(5), DiscountType: "outlet"},
+				{From: day(7), To: day(7), DiscountType: "sale", Ongoing: true},
+			},
+		},
+		{
+			name:         "no discount",
+			observations: []Observation{observation(1, DiscountTypeProper), observation(2, "")},
+		},
+		{
+			name:         "closed by a legacy observation without discount type",
+			observations: []Observation{observation(1, "sale"), observation(2, ""), observation(3, "sale")},
+			want: []DiscountPeriod{
+				{From: day(1), To: day(1), DiscountType: "sale"},
+				{From: day(3), To: day(3), DiscountType: "sale", Ongoing: true},
+			},
+		},
+		{
+			name:         "single ongoing period",
+			observations: []Observation{observation(1, "sale"), observation(2, "sale")},
+			want:         []DiscountPeriod{{From: day(1), To: day(2), DiscountType: "sale", Ongoing: true}},
+		},
+		{
+			name: "no observations",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DiscountPeriods(tt.observations); !slices.Equal(got, tt.want) {
+				t.Errorf("DiscountPeriods() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSkuChanges(t *testing.T) {
+	var (
+		inStock = dto.SkuStatus{IsStockEc: true}
+		soldOut = dto.SkuStatus{IsSoldOut: true}
+	)
+	want := []SkuChange{
+		{At: day(1), SizeName: "S", Status: inStock},
+		{At: day(1), SizeName: "M", Status: dto.SkuStatus{IsStockEc: true, IsStockStore: true}},
+		{At: day(2), SizeName: "M", Status: soldOut},
+		{At: day(4), SizeName: "L", Status: inStock},
+		{At: day(4), SizeName: "M", Removed: true},
+	}
+
+	if got := SkuChanges(loadFixture(t)); !slices.Equal(got, want) {
+		t.Errorf("SkuChanges() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestRecorderAppend(t *testing.T) {
+	dir := t.TempDir()
+	recorder := GetRecorder(dir)
+
+	product := dto.Product{ArticleCode: "IS8022", Price: dto.Price{WithTax: 19800, WithoutTax: 18000, Currency: "JPY", TaxRate: 0.1, TaxIncluded: true, DiscountType: DiscountTypeProper}}
+	for range 2 {
+		if err := recorder.Export([]dto.Product{product, {Name: "no article code"}}); err != nil {
+			t.Fatalf("Export() error = %v", err)
+		}
+	}
+
+	observations, err := Load(dir, "IS8022")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(observations) != 2 || observations[1].Price != product.Price {
+		t.Errorf("Load() = %+v, want 2 observations of %+v", observations, product.Price)
+	}
+
+	if _, err := Load(dir, "HP1234"); err == nil {
+		t.Error("Load() of an article without history error = nil")
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "BAD.jsonl"), []byte("{\"observed_at\":\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(dir, "BAD"); err == nil {
+		t.Error("Load() of a malformed history error = nil")
+	}
+}
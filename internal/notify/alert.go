@@ -0,0 +1,84 @@
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"vcrawler/internal/definition"
+	"vcrawler/internal/dto"
+)
+
+// GetWebhookAlerter returns an alerter that POSTs the stock alerts to the webhooks, with the alert kind as event
+func GetWebhookAlerter(options WebhookOptions) definition.Alerter {
+	return &webhookNotifier{
+		options: options,
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (n *webhookNotifier) Alert(alert dto.StockAlert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, url := range n.options.URLs {
+		if err := n.post(url, string(alert.Kind), body); err != nil {
+			errs = append(errs, fmt.Errorf("error at alerting %s: %w", url, err))
+			continue
+		}
+		slog.Info("stock alert sent to", "webhook", url, "kind", alert.Kind, "article", alert.ArticleCode, "size", alert.SizeName)
+	}
+
+	return errors.Join(errs...)
+}
+
+type writerAlerter struct {
+	w io.Writer
+}
+
+// GetWriterAlerter returns an alerter that prints a line for each stock alert, e.g. to stdout
+func GetWriterAlerter(w io.Writer) definition.Alerter {
+	return &writerAlerter{w: w}
+}
+
+func (a *writerAlerter) Alert(alert dto.StockAlert) error {
+	line := fmt.Sprintf("%s %-8s %s %s size %s %s", alert.At.Local().Format(time.DateTime), alert.Kind, alert.ArticleCode, alert.Name, alert.SizeName, alert.URL)
+	if alert.StockMessage != "" {
+		line += " (" + alert.StockMessage + ")"
+	}
+	_, err := fmt.Fprintln(a.w, line)
+	return err
+}
+
+type fileAlerter struct {
+	fileName string
+}
+
+// GetFileAlerter returns an alerter that appends the stock alerts to a JSON lines file
+func GetFileAlerter(fileName string) definition.Alerter {
+	return &fileAlerter{fileName: fileName}
+}
+
+func (a *fileAlerter) Alert(alert dto.StockAlert) error {
+	b, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(a.fileName, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(b, '\n'))
+	return err
+}
@@ -0,0 +1,70 @@
+package notify
+
+import (
+	"fmt"
+	"log/slog"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"vcrawler/internal/definition"
+	"vcrawler/internal/dto"
+)
+
+type SMTPOptions struct {
+	Host     string
+	Port     int
+	Username string // Authenticates with PLAIN auth when set
+	Password string
+	From     string
+	To       []string
+	Events   []dto.RunStatus // Run statuses to notify, all of them when empty
+}
+
+type emailNotifier struct {
+	options SMTPOptions
+}
+
+// GetEmailNotifier returns a publisher that emails the run summary over SMTP
+func GetEmailNotifier(options SMTPOptions) definition.Publisher {
+	return &emailNotifier{options: options}
+}
+
+func (n *emailNotifier) Publish(report *dto.RunReport) error {
+	if !notifies(n.options.Events, report.Status) {
+		return nil
+	}
+
+	addr := net.JoinHostPort(n.options.Host, strconv.Itoa(n.options.Port))
+
+	var auth smtp.Auth
+	if n.options.Username != "" {
+		auth = smtp.PlainAuth("", n.options.Username, n.options.Password, n.options.Host)
+	}
+
+	if err := smtp.SendMail(addr, auth, n.options.From, n.options.To, n.message(report)); err != nil {
+		return fmt.Errorf("error at sending email: %w", err)
+	}
+
+	slog.Info("run notified to", "email", strings.Join(n.options.To, ", "), "status", report.Status)
+	return nil
+}
+
+func (n *emailNotifier) message(report *dto.RunReport) []byte {
+	subject := fmt.Sprintf("[vcrawler] %s run %s: %s (%d products, %d failures)",
+		report.Store, report.RunID, report.Status, report.ProductCount, report.FailureCount)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", n.options.From)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(n.options.To, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(summary(report).text(), "\n", "\r\n"))
+	return []byte(b.String())
+}
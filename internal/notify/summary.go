@@ -0,0 +1,72 @@
+package notify
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"vcrawler/internal/dto"
+)
+
+// runSummary is the notification payload of a crawl run
+type runSummary struct {
+	RunID        string        `json:"run_id"`
+	Store        string        `json:"store"`
+	Status       dto.RunStatus `json:"status"`
+	Error        string        `json:"error,omitempty"`
+	StartedAt    time.Time     `json:"started_at"`
+	FinishedAt   time.Time     `json:"finished_at"`
+	Duration     string        `json:"duration"`
+	ProductCount int           `json:"product_count"`
+	FailureCount int           `json:"failure_count"`
+	Outputs      []string      `json:"outputs"`
+}
+
+func summary(report *dto.RunReport) runSummary {
+	s := runSummary{
+		RunID:        report.RunID,
+		Store:        report.Store,
+		Status:       report.Status,
+		Error:        report.Error,
+		StartedAt:    report.StartedAt,
+		FinishedAt:   report.FinishedAt,
+		Duration:     report.Duration,
+		ProductCount: report.ProductCount,
+		FailureCount: report.FailureCount,
+	}
+
+	// The uploaded locations are more useful than the local files when there are any
+	outputs := append(slices.Clone(report.Outputs), report.ReportFile, report.FailureManifest)
+	if len(report.Uploads) > 0 {
+		outputs = report.Uploads
+	}
+	// The report and manifest files are empty when they couldn't be written
+	for _, output := range outputs {
+		if output != "" {
+			s.Outputs = append(s.Outputs, output)
+		}
+	}
+
+	return s
+}
+
+// text returns the summary as plain text, for the email body
+func (s runSummary) text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Store:    %s\n", s.Store)
+	fmt.Fprintf(&b, "Run:      %s\n", s.RunID)
+	fmt.Fprintf(&b, "Status:   %s\n", s.Status)
+	if s.Error != "" {
+		fmt.Fprintf(&b, "Error:    %s\n", s.Error)
+	}
+	fmt.Fprintf(&b, "Started:  %s\n", s.StartedAt.Format(time.RFC3339))
+	fmt.Fprintf(&b, "Duration: %s\n", s.Duration)
+	fmt.Fprintf(&b, "Products: %d\n", s.ProductCount)
+	fmt.Fprintf(&b, "Failures: %d\n", s.FailureCount)
+	b.WriteString("\nOutputs:\n")
+	for _, output := range s.Outputs {
+		fmt.Fprintf(&b, "  %s\n", output)
+	}
+	return b.String()
+}
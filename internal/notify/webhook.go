@@ -0,0 +1,132 @@
+package notify
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"vcrawler/internal/definition"
+	"vcrawler/internal/dto"
+)
+
+const (
+	// SignatureHeader holds the hex HMAC-SHA256 of "<timestamp>.<body>" with the webhook secret
+	SignatureHeader = "X-Vcrawler-Signature"
+	TimestampHeader = "X-Vcrawler-Timestamp"
+	EventHeader     = "X-Vcrawler-Event"
+)
+
+// webhookBackoff is the delay before the first retry, doubled at each retry
+var webhookBackoff = 2 * time.Second
+
+type WebhookOptions struct {
+	URLs    []string
+	Secret  string          // Signs the requests when set
+	Events  []dto.RunStatus // Run statuses to notify, all of them when empty
+	Retries int             // Retries of a failed request, with exponential backoff
+}
+
+type webhookNotifier struct {
+	options WebhookOptions
+	client  *http.Client
+}
+
+// GetWebhookNotifier returns a publisher that POSTs the run summary to the webhooks
+func GetWebhookNotifier(options WebhookOptions) definition.Publisher {
+	return &webhookNotifier{
+		options: options,
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (n *webhookNotifier) Publish(report *dto.RunReport) error {
+	if !notifies(n.options.Events, report.Status) {
+		return nil
+	}
+
+	body, err := json.Marshal(summary(report))
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, url := range n.options.URLs {
+		if err := n.post(url, string(report.Status), body); err != nil {
+			errs = append(errs, fmt.Errorf("error at notifying %s: %w", url, err))
+			continue
+		}
+		slog.Info("run notified to", "webhook", url, "status", report.Status)
+	}
+
+	return errors.Join(errs...)
+}
+
+func (n *webhookNotifier) post(url, event string, body []byte) error {
+	var err error
+	for attempt := 0; attempt <= n.options.Retries; attempt++ {
+		if attempt > 0 {
+			backoff := webhookBackoff << (attempt - 1)
+			slog.Warn("retrying webhook", "webhook", url, "attempt", attempt, "backoff", backoff, "cause", err)
+			time.Sleep(backoff)
+		}
+
+		var retry bool
+		retry, err = n.send(url, event, body)
+		if err == nil || !retry {
+			return err
+		}
+	}
+	return err
+}
+
+// send sends the request once, and tells whether it can be retried when it fails
+func (n *webhookNotifier) send(url, event string, body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, event)
+	req.Header.Set(TimestampHeader, timestamp)
+	if n.options.Secret != "" {
+		req.Header.Set(SignatureHeader, "sha256="+Sign(n.options.Secret, timestamp, body))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 300 {
+		retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+		return retry, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return false, nil
+}
+
+// Sign returns the hex HMAC-SHA256 of the timestamp and body, for receivers to verify the requests
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func notifies(events []dto.RunStatus, status dto.RunStatus) bool {
+	return len(events) == 0 || slices.Contains(events, status)
+}
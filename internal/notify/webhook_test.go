@@ -0,0 +1,103 @@
+package notify
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"vcrawler/internal/dto"
+)
+
+func TestWebhookSignature(t *testing.T) {
+	const secret = "s3cr3t"
+
+	var (
+		body      []byte
+		timestamp string
+		signature string
+		event     string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		timestamp = r.Header.Get(TimestampHeader)
+		signature = r.Header.Get(SignatureHeader)
+		event = r.Header.Get(EventHeader)
+	}))
+	defer srv.Close()
+
+	report := &dto.RunReport{
+		RunID:   "20240901T101500-3f2a9c1e",
+		Store:   "adidas",
+		Status:  dto.RunStatusPartial,
+		Outputs: []string{"products.csv", ""},
+		// No ReportFile, the report couldn't be written
+		FailureManifest: "failures.json",
+	}
+	notifier := GetWebhookNotifier(WebhookOptions{URLs: []string{srv.URL}, Secret: secret})
+	if err := notifier.Publish(report); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	// The signature is recomputed as receivers do, from the documented format
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "." + string(body)))
+	if want := "sha256=" + hex.EncodeToString(mac.Sum(nil)); signature != want {
+		t.Errorf("signature = %s, want %s", signature, want)
+	}
+	if event != string(dto.RunStatusPartial) {
+		t.Errorf("event = %s, want %s", event, dto.RunStatusPartial)
+	}
+
+	var got runSummary
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatalf("unmarshal summary: %v", err)
+	}
+	if want := []string{"products.csv", "failures.json"}; !slices.Equal(got.Outputs, want) {
+		t.Errorf("outputs = %q, want %q", got.Outputs, want)
+	}
+}
+
+func TestWebhookRetries(t *testing.T) {
+	defer func(backoff time.Duration) { webhookBackoff = backoff }(webhookBackoff)
+	webhookBackoff = time.Millisecond
+
+	tests := []struct {
+		name     string
+		status   int
+		retries  int
+		attempts int32
+	}{
+		{"server error is retried", http.StatusBadGateway, 2, 3},
+		{"too many requests is retried", http.StatusTooManyRequests, 1, 2},
+		{"client error is not retried", http.StatusBadRequest, 2, 1},
+		{"success is sent once", http.StatusOK, 2, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts.Add(1)
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			notifier := GetWebhookNotifier(WebhookOptions{URLs: []string{srv.URL}, Retries: tt.retries})
+			err := notifier.Publish(&dto.RunReport{Status: dto.RunStatusSuccess})
+			if (err != nil) != (tt.status >= 300) {
+				t.Errorf("Publish() error = %v", err)
+			}
+			if got := attempts.Load(); got != tt.attempts {
+				t.Errorf("attempts = %d, want %d", got, tt.attempts)
+			}
+		})
+	}
+}
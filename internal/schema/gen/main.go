@@ -0,0 +1,21 @@
+// Command gen generates the JSON Schema of dto.Product into product.schema.json
+package main
+
+import (
+	"log"
+	"os"
+
+	"vcrawler/internal/dto"
+	"vcrawler/internal/schema"
+)
+
+func main() {
+	b, err := schema.Generate(dto.Product{}, schema.ProductID, "Product", dto.ProductSchemaVersion)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := os.WriteFile("product.schema.json", append(b, '\n'), 0644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,173 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const draft = "https://json-schema.org/draft/2020-12/schema"
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Generate returns the JSON Schema of the type from its json tags. The named
+// structs go to $defs, fields without omitempty are required, and the
+// jsonschema tag adds constraints, e.g. `jsonschema:"minLength=1,format=uri"`.
+func Generate(v any, id, title, version string) ([]byte, error) {
+	g := &generator{defs: map[string]any{}}
+
+	root, err := g.structSchema(reflect.TypeOf(v))
+	if err != nil {
+		return nil, err
+	}
+
+	root["$schema"] = draft
+	root["$id"] = id
+	root["title"] = title
+	root["version"] = version
+	root["$defs"] = g.defs
+
+	return json.MarshalIndent(root, "", "  ")
+}
+
+type generator struct {
+	defs map[string]any
+}
+
+func (g *generator) schema(t reflect.Type) (map[string]any, error) {
+	switch t.Kind() {
+	case reflect.Pointer:
+		s, err := g.schema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return nullable(s), nil
+	case reflect.String:
+		return map[string]any{"type": "string"}, nil
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}, nil
+	case reflect.Slice, reflect.Array:
+		items, err := g.schema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		// A nil slice is encoded as null
+		return nullable(map[string]any{"type": "array", "items": items}), nil
+	case reflect.Map:
+		values, err := g.schema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return nullable(map[string]any{"type": "object", "additionalProperties": values}), nil
+	case reflect.Struct:
+		if t == timeType {
+			return map[string]any{"type": "string", "format": "date-time"}, nil
+		}
+
+		if _, ok := g.defs[t.Name()]; !ok {
+			// Reserve the name first for the recursive types
+			g.defs[t.Name()] = nil
+			s, err := g.structSchema(t)
+			if err != nil {
+				return nil, err
+			}
+			g.defs[t.Name()] = s
+		}
+		return map[string]any{"$ref": "#/$defs/" + t.Name()}, nil
+	case reflect.Interface:
+		return map[string]any{}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported type %s", t)
+}
+
+func (g *generator) structSchema(t reflect.Type) (map[string]any, error) {
+	properties := map[string]any{}
+	required := []string{}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		s, err := g.schema(field.Type)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", t.Name(), field.Name, err)
+		}
+
+		if err := constrain(s, field.Tag.Get("jsonschema")); err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", t.Name(), field.Name, err)
+		}
+
+		properties[name] = s
+		if !strings.Contains(opts, "omitempty") {
+			required = append(required, name)
+		}
+	}
+
+	return map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}, nil
+}
+
+// constrain adds the constraints of the jsonschema tag to the schema
+func constrain(s map[string]any, tag string) error {
+	if tag == "" {
+		return nil
+	}
+
+	for _, constraint := range strings.Split(tag, ",") {
+		key, value, _ := strings.Cut(constraint, "=")
+
+		switch key {
+		case "minLength", "maxLength", "minItems", "maxItems":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("invalid %s: %w", key, err)
+			}
+			s[key] = n
+		case "minimum", "maximum":
+			n, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return fmt.Errorf("invalid %s: %w", key, err)
+			}
+			s[key] = n
+		case "pattern", "format", "description":
+			s[key] = value
+		case "enum":
+			s[key] = strings.Split(value, "|")
+		default:
+			return fmt.Errorf("unknown jsonschema constraint %q", key)
+		}
+	}
+
+	return nil
+}
+
+func nullable(s map[string]any) map[string]any {
+	if t, ok := s["type"].(string); ok {
+		s["type"] = []string{t, "null"}
+		return s
+	}
+	return map[string]any{"anyOf": []any{s, map[string]any{"type": "null"}}}
+}
@@ -0,0 +1,83 @@
+package schema
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"vcrawler/internal/dto"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+//go:generate go run ./gen
+
+// ProductID is the $id of the product schema, versioned with dto.ProductSchemaVersion
+const ProductID = "https://github.com/0xTanvir/VenturaCrawler/schemas/product/v" + dto.ProductSchemaVersion + ".json"
+
+// Product is the JSON Schema of dto.Product, generated by go generate
+//
+//go:embed product.schema.json
+var Product []byte
+
+type Validator struct {
+	schema *jsonschema.Schema
+}
+
+func GetValidator() (*Validator, error) {
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(Product))
+	if err != nil {
+		return nil, err
+	}
+
+	c := jsonschema.NewCompiler()
+	c.AssertFormat()
+	if err := c.AddResource(ProductID, doc); err != nil {
+		return nil, err
+	}
+
+	s, err := c.Compile(ProductID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Validator{schema: s}, nil
+}
+
+// Validate returns the violations of the product
+func (v *Validator) Validate(product dto.Product) ([]dto.Violation, error) {
+	b, err := json.Marshal(product)
+	if err != nil {
+		return nil, err
+	}
+
+	inst, err := jsonschema.UnmarshalJSON(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+
+	err = v.schema.Validate(inst)
+	if err == nil {
+		return nil, nil
+	}
+
+	var ve *jsonschema.ValidationError
+	if !errors.As(err, &ve) {
+		return nil, err
+	}
+
+	var violations []dto.Violation
+	for _, unit := range ve.BasicOutput().Errors {
+		if unit.Error == nil {
+			continue
+		}
+		violations = append(violations, dto.Violation{
+			ArticleCode: product.ArticleCode,
+			Location:    unit.InstanceLocation,
+			Message:     fmt.Sprint(unit.Error),
+		})
+	}
+	return violations, nil
+}
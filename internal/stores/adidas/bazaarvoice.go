@@ -0,0 +1,157 @@
+package adidas
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"vcrawler/internal/dto"
+	"vcrawler/pkg/helpers"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/gocolly/colly/v2"
+)
+
+const (
+	reviewsURL = "https://adidasjp.ugc.bazaarvoice.com/7896-ja_jp/%s/reviews.djs?format=embeddedhtml&productattribute_itemKcod=%s&page=%d"
+
+	// bvReviewsMaterial is the material of the .djs payload holding the page of reviews
+	bvReviewsMaterial = "BVRRSourceID"
+)
+
+var errNoMaterials = errors.New("no bazaarvoice materials in the response")
+
+// bvMaterials decodes the materials of a Bazaarvoice .djs payload, the HTML fragments by container id.
+// The payload is JavaScript embedding them as a JSON object, e.g. var materials={"BVRRSourceID":"<div ...>"}
+func bvMaterials(body []byte) (map[string]string, error) {
+	start := bytes.Index(body, []byte(`{"BVRR`))
+	if start < 0 {
+		return nil, errNoMaterials
+	}
+
+	// The decoder stops at the end of the object, ignoring the JavaScript after it
+	var raw map[string]json.RawMessage
+	if err := json.NewDecoder(bytes.NewReader(body[start:])).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("error at decoding bazaarvoice materials: %w", err)
+	}
+
+	materials := make(map[string]string, len(raw))
+	for id, value := range raw {
+		var html string
+		if err := json.Unmarshal(value, &html); err != nil {
+			continue // Not an HTML fragment
+		}
+		materials[id] = html
+	}
+	return materials, nil
+}
+
+// GetReviews pages through the Bazaarvoice reviews of the article, up to maxReviews
+func GetReviews(articleCode, modelCode string, maxReviews int) ([]dto.Review, error) {
+	return getReviews(helpers.GetCollector(), func(page int) string {
+		return fmt.Sprintf(reviewsURL, modelCode, articleCode, page)
+	}, maxReviews)
+}
+
+// getReviews visits the review pages from the first one while they have a next page, up to maxReviews
+func getReviews(c *colly.Collector, pageURL func(page int) string, maxReviews int) ([]dto.Review, error) {
+	var (
+		reviews []dto.Review
+		errs    []error
+		page    = 1
+	)
+
+	c.OnRequest(func(r *colly.Request) {
+		r.Headers.Set("User-Agent", helpers.GetRandomUserAgent())
+	})
+
+	c.OnResponse(func(r *colly.Response) {
+		materials, err := bvMaterials(r.Body)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", r.Request.URL, err))
+			return
+		}
+
+		doc, err := goquery.NewDocumentFromReader(strings.NewReader(materials[bvReviewsMaterial]))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", r.Request.URL, err))
+			return
+		}
+
+		doc.Find("div.BVRRContentReview").EachWithBreak(func(i int, s *goquery.Selection) bool {
+			reviews = append(reviews, parseReview(s))
+			return len(reviews) < maxReviews
+		})
+
+		if len(reviews) < maxReviews && doc.Find(".BVRRNextPage a").Length() > 0 {
+			page++
+			if err := c.Visit(pageURL(page)); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	})
+
+	c.OnError(func(r *colly.Response, err error) {
+		slog.Error("error at fetching:", "url", r.Request.URL.String(), "error", err)
+		errs = append(errs, fmt.Errorf("%s: %w", r.Request.URL, err))
+	})
+
+	if err := c.Visit(pageURL(page)); err != nil {
+		return nil, err
+	}
+
+	c.Wait()
+
+	return reviews, errors.Join(errs...)
+}
+
+// parseReview returns the review of a BVRRContentReview element, marked up with schema.org microdata
+func parseReview(s *goquery.Selection) dto.Review {
+	review := dto.Review{
+		AuthorName:    text(s.Find(".BVRRNickname")),
+		DatePublished: s.Find("[itemprop=datePublished]").AttrOr("content", text(s.Find(".BVRRReviewDate"))),
+		Title:         text(s.Find(".BVRRReviewTitle")),
+		Body:          text(s.Find(".BVRRReviewText")),
+		RatingValue:   text(s.Find("[itemprop=ratingValue]").First()),
+		BestRating:    text(s.Find("[itemprop=bestRating]").First()),
+		HelpfulYes:    count(s.Find(".BVDI_FVPositive .BVDINumber")),
+		HelpfulNo:     count(s.Find(".BVDI_FVNegative .BVDINumber")),
+	}
+
+	s.Find(".BVRRContextDataValueContainer").Each(func(i int, c *goquery.Selection) {
+		review.Attributes = append(review.Attributes, dto.ReviewAttribute{
+			Label: strings.TrimSuffix(text(c.Find(".BVRRContextDataValuePrefix")), ":"),
+			Value: text(c.Find(".BVRRContextDataValue")),
+		})
+	})
+
+	// Secondary ratings like the fit are sliders, whose value is the alt text of the selected position
+	s.Find("div.BVRRRatingEntry").Each(func(i int, e *goquery.Selection) {
+		label := text(e.Find("div.BVRRRatingHeader"))
+		value := strings.TrimSpace(e.Find("div.BVRRRatingRadioImage img").AttrOr("alt", ""))
+		if label != "" && value != "" {
+			review.Attributes = append(review.Attributes, dto.ReviewAttribute{Label: label, Value: value})
+		}
+	})
+
+	s.Find(".BVRRPhotoThumbnail img, .BVRRReviewPhotoContainer img").Each(func(i int, img *goquery.Selection) {
+		if src := img.AttrOr("src", ""); src != "" {
+			review.Photos = append(review.Photos, src)
+		}
+	})
+
+	return review
+}
+
+func text(s *goquery.Selection) string {
+	return strings.TrimSpace(s.Text())
+}
+
+func count(s *goquery.Selection) int {
+	n, _ := strconv.Atoi(strings.Trim(text(s.First()), "()"))
+	return n
+}
@@ -0,0 +1,134 @@
+package adidas
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"vcrawler/internal/dto"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/gocolly/colly/v2"
+)
+
+// testdata/reviews_page*.djs are trimmed .djs payloads of the reviews of an article, two per page
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	b, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestBvMaterials(t *testing.T) {
+	materials, err := bvMaterials(readFixture(t, "reviews_page1.djs"))
+	if err != nil {
+		t.Fatalf("bvMaterials() error = %v", err)
+	}
+
+	for _, id := range []string{bvReviewsMaterial, bvRatingSummaryMaterial} {
+		if !strings.HasPrefix(materials[id], "<div") {
+			t.Errorf("material %s = %.40q, want an HTML fragment", id, materials[id])
+		}
+	}
+	// The JavaScript after the object isn't decoded
+	if len(materials) != 3 {
+		t.Errorf("materials = %d, want 3", len(materials))
+	}
+}
+
+func TestParseReview(t *testing.T) {
+	materials, err := bvMaterials(readFixture(t, "reviews_page1.djs"))
+	if err != nil {
+		t.Fatalf("bvMaterials() error = %v", err)
+	}
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(materials[bvReviewsMaterial]))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := parseReview(doc.Find("div.BVRRContentReview").First())
+	want := dto.Review{
+		AuthorName:    "taro",
+		DatePublished: "2024-08-01",
+		Body:          "普段Mですが、ゆったり着られます。",
+		BestRating:    "5",
+		RatingValue:   "5",
+		Title:         "着心地が良い",
+		Attributes:    []dto.ReviewAttribute{{Label: "購入サイズ", Value: "M"}, {Label: "フィット感", Value: "普通"}},
+		HelpfulYes:    3,
+		HelpfulNo:     0,
+		Photos:        []string{"https://photos-us.bazaarvoice.com/photo/2/cGhvdG86YWRpZGFzanA/1.jpg"},
+	}
+	if !reviewEqual(got, want) {
+		t.Errorf("parseReview() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func reviewEqual(a, b dto.Review) bool {
+	return a.AuthorName == b.AuthorName && a.DatePublished == b.DatePublished && a.Body == b.Body &&
+		a.BestRating == b.BestRating && a.RatingValue == b.RatingValue && a.Title == b.Title &&
+		slices.Equal(a.Attributes, b.Attributes) && a.HelpfulYes == b.HelpfulYes && a.HelpfulNo == b.HelpfulNo &&
+		slices.Equal(a.Photos, b.Photos)
+}
+
+func TestGetReviewsPaging(t *testing.T) {
+	pages := map[string][]byte{
+		"1": readFixture(t, "reviews_page1.djs"),
+		"2": readFixture(t, "reviews_page2.djs"),
+	}
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		page, ok := pages[r.URL.Query().Get("page")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(page)
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name       string
+		maxReviews int
+		authors    []string
+		requests   int32
+	}{
+		// The second page has no next page link
+		{"stops on the last page", 10, []string{"taro", "hanako", "jiro"}, 2},
+		{"capped within a page", 1, []string{"taro"}, 1},
+		{"capped at the end of a page", 2, []string{"taro", "hanako"}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests.Store(0)
+			reviews, err := getReviews(colly.NewCollector(), func(page int) string {
+				return srv.URL + "/reviews.djs?page=" + strconv.Itoa(page)
+			}, tt.maxReviews)
+			if err != nil {
+				t.Fatalf("getReviews() error = %v", err)
+			}
+
+			var authors []string
+			for _, review := range reviews {
+				authors = append(authors, review.AuthorName)
+			}
+			if !slices.Equal(authors, tt.authors) {
+				t.Errorf("reviews = %q, want %q", authors, tt.authors)
+			}
+			if got := requests.Load(); got != tt.requests {
+				t.Errorf("requests = %d, want %d", got, tt.requests)
+			}
+		})
+	}
+}
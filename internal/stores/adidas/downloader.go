@@ -0,0 +1,32 @@
+package adidas
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"vcrawler/pkg/helpers"
+)
+
+var imageClient = &http.Client{Timeout: time.Minute}
+
+func (s *scraper) DownloadImage(url string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", helpers.GetRandomUserAgent())
+	req.Header.Set("Referer", baseURL+"/")
+
+	resp, err := imageClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
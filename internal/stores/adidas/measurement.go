@@ -0,0 +1,117 @@
+package adidas
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"vcrawler/internal/dto"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// measurementType is the canonical key and English label of a size chart measurement type
+type measurementType struct {
+	Key   string
+	Label string
+}
+
+// measurementTypes maps the size chart headers to their canonical measurement type
+var measurementTypes = map[string]measurementType{
+	"胸囲":     {"chest", "Chest"},
+	"うしろ着丈":  {"back_length", "Back length"},
+	"後ろ着丈":   {"back_length", "Back length"},
+	"ラグラン袖丈": {"raglan_sleeve_length", "Raglan sleeve length"},
+	"袖丈":     {"sleeve_length", "Sleeve length"},
+	"袖口幅":    {"cuff_width", "Cuff width"},
+	"ウエスト":   {"waist", "Waist"},
+	"ウェスト":   {"waist", "Waist"},
+	"ヒップ":    {"hip", "Hip"},
+	"股上":     {"rise", "Rise"},
+	"股下":     {"inseam", "Inseam"},
+	"裾回り":    {"hem_circumference", "Hem circumference"},
+	"フード長さ":  {"hood_length", "Hood length"},
+	"身長":     {"height", "Height"},
+	"足長":     {"foot_length", "Foot length"},
+}
+
+// measurementValueRegexp matches values like "72cm", "約72cm", "96-104cm", "96cm〜104cm" and "96〜104"
+var measurementValueRegexp = regexp.MustCompile(`^(?:約)?(\d+(?:\.\d+)?)(?:(?:cm|mm|inch|in|インチ)?(?:-|~|〜|–)(\d+(?:\.\d+)?))?(cm|mm|inch|in|インチ)?$`)
+
+// measurementUnitRegexp matches the unit of a header like "胸囲(cm)"
+var measurementUnitRegexp = regexp.MustCompile(`\((cm|mm|inch|in|インチ)\)$`)
+
+// toMeasurement returns the measurement of a size chart cell, with its canonical type and numeric range.
+// The value is kept in Raw when it can't be parsed.
+func toMeasurement(header, value string) dto.Measurement {
+	m := dto.Measurement{Type: header, Value: value}
+
+	// Full-width digits, parentheses and tildes are folded by NFKC
+	header = strings.ReplaceAll(norm.NFKC.String(header), " ", "")
+	var headerUnit string
+	if match := measurementUnitRegexp.FindStringSubmatch(header); match != nil {
+		headerUnit = match[1]
+		header = strings.TrimSuffix(header, match[0])
+	}
+	if t, ok := measurementTypes[header]; ok {
+		m.Key, m.Label = t.Key, t.Label
+	}
+
+	match := measurementValueRegexp.FindStringSubmatch(strings.ReplaceAll(norm.NFKC.String(value), " ", ""))
+	if match == nil {
+		m.Raw = value
+		return m
+	}
+	m.Min, _ = strconv.ParseFloat(match[1], 64)
+	m.Max = m.Min
+	if match[2] != "" {
+		m.Max, _ = strconv.ParseFloat(match[2], 64)
+	}
+	// Ranges written largest first, like "104-96cm"
+	if m.Min > m.Max {
+		m.Min, m.Max = m.Max, m.Min
+	}
+	m.Unit = measurementUnit(match[3], headerUnit)
+	return m
+}
+
+// measurementUnit returns the canonical unit of the value, or of its header when the value has none
+func measurementUnit(valueUnit, headerUnit string) string {
+	unit := valueUnit
+	if unit == "" {
+		unit = headerUnit
+	}
+	switch unit {
+	case "in", "インチ":
+		return "inch"
+	}
+	return unit
+}
+
+// fillChartUnit gives the parsed measurements without unit the unit of the other measurements of the chart,
+// when they all have the same one, e.g. a "96～104" column next to "胸囲(cm)" columns
+func fillChartUnit(sizeCharts []dto.SizeChart) {
+	var unit string
+	for _, sizeChart := range sizeCharts {
+		for _, m := range sizeChart.Measurements {
+			switch {
+			case m.Unit == "":
+			case unit == "":
+				unit = m.Unit
+			case unit != m.Unit:
+				return
+			}
+		}
+	}
+	if unit == "" {
+		return
+	}
+
+	for _, sizeChart := range sizeCharts {
+		for i, m := range sizeChart.Measurements {
+			if m.Unit == "" && m.Raw == "" {
+				sizeChart.Measurements[i].Unit = unit
+			}
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package adidas
+
+import (
+	"slices"
+	"testing"
+
+	"vcrawler/internal/dto"
+)
+
+func TestToMeasurementRange(t *testing.T) {
+	tests := []struct {
+		header, value string
+		min, max      float64
+		unit, raw     string
+	}{
+		{"胸囲", "96-104cm", 96, 104, "cm", ""},
+		{"胸囲", "104-96cm", 96, 104, "cm", ""},
+		{"ウエスト(cm)", "約72", 72, 72, "cm", ""},
+		{"表示サイズ", "M", 0, 0, "", "M"},
+	}
+
+	for _, tt := range tests {
+		m := toMeasurement(tt.header, tt.value)
+		if m.Min != tt.min || m.Max != tt.max || m.Unit != tt.unit || m.Raw != tt.raw {
+			t.Errorf("toMeasurement(%q, %q) = %+v, want min %v max %v unit %q raw %q", tt.header, tt.value, m, tt.min, tt.max, tt.unit, tt.raw)
+		}
+	}
+}
+
+func TestFillChartUnit(t *testing.T) {
+	chart := func(values ...string) []dto.SizeChart {
+		var measurements []dto.Measurement
+		for _, value := range values {
+			measurements = append(measurements, toMeasurement("胸囲", value))
+		}
+		return []dto.SizeChart{{Size: "M", Measurements: measurements}}
+	}
+
+	tests := []struct {
+		name   string
+		charts []dto.SizeChart
+		want   []string
+	}{
+		{"unit of the chart", chart("96～104", "72cm", "M"), []string{"cm", "cm", ""}},
+		{"no unit in the chart", chart("96～104", "78.3"), []string{"", ""}},
+		{"different units in the chart", chart("96～104", "72cm", "28inch"), []string{"", "cm", "inch"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fillChartUnit(tt.charts)
+			var got []string
+			for _, m := range tt.charts[0].Measurements {
+				got = append(got, m.Unit)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("units = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,6 +3,9 @@ package adidas
 import (
 	"fmt"
 	"log/slog"
+	"math"
+	"path"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -73,6 +76,15 @@ type Article struct {
 	SportCode           string `json:"sport_code"`
 	SportName           string `json:"sport_name"`
 	SportSlug           string `json:"sport_slug"`
+
+	// ImageNameData holds the images shown on the listing page
+	ImageNameData ImageNameData `json:"image_name_data"`
+}
+
+// IsLimited tells whether the article is a limited release
+func (a Article) IsLimited() bool {
+	limited, _ := strconv.ParseBool(a.ItemLimited)
+	return limited
 }
 
 // Articles struct to hold multiple articles
@@ -111,38 +123,66 @@ func (plr ProductListResponse) CurrentPage() int {
 	return pageNo
 }
 
-func (pr ProductResponse) ToProduct() dto.Product {
-	rating, recommendedRate, ratingSense := GetRatingSense(pr.Product.Article.ArticleCode, pr.Product.Model.ModelCode)
+// ToProduct returns the product of the detail response, completed with its article of the listing page when it was listed
+func (pr ProductResponse) ToProduct(listing Article) dto.Product {
+	rating, missingReason := pr.Rating()
 
 	return dto.Product{
-		Name:        pr.Product.Article.Name,
-		ModelCode:   pr.Product.Model.ModelCode,
-		ArticleCode: pr.Product.Article.ArticleCode,
-		Price: dto.Price{
-			WithTax:      pr.Product.Article.Price.Current.WithTax,
-			WithoutTax:   strconv.FormatFloat(pr.Product.Article.Price.Current.WithoutTax, 'f', 6, 64),
-			DiscountType: pr.Product.Article.Price.DiscountType,
-		},
-		URL:             fmt.Sprintf("%s/products/%s", baseURL, pr.Product.Article.ArticleCode),
-		Images:          pr.Images(),
-		Breadcrumb:      pr.Breadcrumb(),
-		Breadcrumbs:     pr.Breadcrumbs(),
-		KWs:             pr.KWs(),
-		Categories:      pr.Categories(),
-		SizeChoice:      pr.SizeChoice(),
-		Coordinates:     pr.Coordinates(),
-		Description:     pr.Description(),
-		Skus:            pr.Skus(),
-		SizeCharts:      GetSizeCharts(pr.Product.Model.ModelCode),
-		Technologies:    pr.Technologies(),
-		ReviewCount:     fmt.Sprintf("%d", pr.Product.Model.Review.ReviewCount),
-		Reviews:         pr.Reviews(),
-		Rating:          rating,
-		RecommendedRate: recommendedRate,
-		RatingSenses:    ratingSense,
+		Name:                pr.Product.Article.Name,
+		ModelCode:           pr.Product.Model.ModelCode,
+		ArticleCode:         pr.Product.Article.ArticleCode,
+		ColorName:           listing.ColorName,
+		BrandName:           listing.BrandName,
+		SportName:           listing.SportName,
+		GenderName:          listing.GenderName,
+		GenreName:           listing.GenreName,
+		ReleaseDate:         listing.ReleaseDate,
+		ItemStatus:          listing.ItemStatus,
+		Limited:             listing.IsLimited(),
+		OptionalLabel:       listing.OptionalLabel,
+		FixedPrice:          listing.PriceFixed,
+		DiscountPrice:       listing.PriceDiscount,
+		Price:               pr.Product.Article.Price.ToPrice(),
+		URL:                 fmt.Sprintf("%s/products/%s", baseURL, pr.Product.Article.ArticleCode),
+		Images:              pr.Images(),
+		ImageDetails:        pr.ImageDetails(listing.ImageNameData),
+		Breadcrumb:          pr.Breadcrumb(),
+		Breadcrumbs:         pr.Breadcrumbs(),
+		KWs:                 pr.KWs(),
+		Categories:          pr.Categories(),
+		SizeChoice:          pr.SizeChoice(),
+		Coordinates:         pr.Coordinates(),
+		Description:         pr.Description(),
+		Skus:                pr.Skus(),
+		SizeCharts:          pr.SizeCharts(),
+		Technologies:        pr.Technologies(),
+		ReviewCount:         fmt.Sprintf("%d", pr.Product.Model.Review.ReviewCount),
+		Reviews:             pr.Reviews(),
+		Rating:              rating.Rating,
+		RecommendedRate:     rating.RecommendedRate,
+		RatingSenses:        rating.Senses,
+		AverageRating:       rating.AverageRating,
+		RecommendedPercent:  rating.RecommendedPercent,
+		RatingHistogram:     rating.Histogram,
+		RatingMissingReason: missingReason,
 	}
 }
 
+// Rating returns the rating summary of the product on Bazaarvoice, or the reason it is missing
+func (pr ProductResponse) Rating() (RatingSummary, dto.RatingMissingReason) {
+	// Products without reviews have no rating summary
+	if pr.Product.Model.Review.ReviewCount == 0 {
+		return RatingSummary{}, dto.RatingMissingNoReviews
+	}
+
+	rating, err := GetRatingSense(pr.Product.Article.ArticleCode, pr.Product.Model.ModelCode)
+	if err != nil {
+		slog.Warn("rating missing", "article", pr.Product.Article.ArticleCode, "cause", err)
+		return rating, ratingMissingReason(err)
+	}
+	return rating, ""
+}
+
 func (pr ProductResponse) Reviews() []dto.Review {
 	var result []dto.Review
 	for _, review := range pr.Product.Model.Review.ReviewSeoLd {
@@ -170,9 +210,16 @@ func (pr ProductResponse) Technologies() []dto.Technology {
 
 func (pr ProductResponse) Skus() []dto.Sku {
 	var result []dto.Sku
+	context := pr.SizeContext()
 	for _, sku := range pr.Product.Article.Skus {
 		result = append(result, dto.Sku{
-			SizeName: sku.SizeName,
+			SizeName:       sku.SizeName,
+			CanonicalSize:  NormalizeSize(sku.SizeName, context),
+			Code:           sku.ArticleCode,
+			StockMessage:   sku.PurchaseInfo.StockMessage,
+			StockIcon:      sku.PurchaseInfo.Icon,
+			CanAddToCart:   sku.AddToCartButton.Enabled,
+			AddToCartLabel: sku.AddToCartButton.Label,
 			Status: dto.SkuStatus{
 				IsStockEc:    sku.Status.InStockEc,
 				IsStockStore: sku.Status.InStockStore,
@@ -183,6 +230,32 @@ func (pr ProductResponse) Skus() []dto.Sku {
 	return result
 }
 
+// SizeCharts returns the size charts of the model, with the canonical size of each row
+func (pr ProductResponse) SizeCharts() []dto.SizeChart {
+	sizeCharts := GetSizeCharts(pr.Product.Model.ModelCode)
+	context := pr.SizeContext()
+	for i := range sizeCharts {
+		sizeCharts[i].CanonicalSize = NormalizeSize(sizeCharts[i].Size, context)
+	}
+	return sizeCharts
+}
+
+// SizeContext returns how to read the numeric sizes of the product, from the gender and category of its breadcrumbs
+func (pr ProductResponse) SizeContext() SizeContext {
+	var context SizeContext
+	for _, bc := range pr.Page.Breadcrumbs {
+		for _, param := range bc.QueryParams {
+			switch {
+			case param.Key == "gender" && param.Value == "kids":
+				context.Kids = true
+			case param.Key == "category" && param.Value == "shoes":
+				context.Shoes = true
+			}
+		}
+	}
+	return context
+}
+
 func (pr ProductResponse) Description() dto.Description {
 	return dto.Description{
 		Title:   pr.Product.Article.Description.Messages.Title,
@@ -198,11 +271,7 @@ func (pr ProductResponse) Coordinates() []dto.Coordinate {
 			ProductName:  article.Name,
 			ProductURL:   fmt.Sprintf("%s/products/%s", baseURL, article.ArticleCode),
 			ProductImage: fmt.Sprintf("%s%s", baseURL, article.Image),
-			ProductPrice: dto.Price{
-				WithTax:      article.Price.Current.WithTax,
-				WithoutTax:   strconv.FormatFloat(article.Price.Current.WithoutTax, 'f', 6, 64),
-				DiscountType: article.Price.DiscountType,
-			},
+			ProductPrice: article.Price.ToPrice(),
 		})
 	}
 	return result
@@ -269,6 +338,52 @@ func (pr ProductResponse) Images() []string {
 	return images
 }
 
+func (pr ProductResponse) ImageDetails(names ImageNameData) []dto.Image {
+	var images []dto.Image
+	for i, detail := range pr.Product.Article.Image.Details {
+		images = append(images, dto.Image{
+			Large:   imageURL(detail.ImageUrl.Large),
+			Medium:  imageURL(detail.ImageUrl.Medium),
+			Small:   imageURL(detail.ImageUrl.Small),
+			Caption: detail.Caption,
+			View:    imageView(detail.ImageUrl.Large),
+			Order:   i + 1,
+			Listing: names.listing(detail.ImageUrl.Large),
+		})
+	}
+	return images
+}
+
+// imageFileRe matches the image file names, e.g. zz-IS8022-on_model-standard_view-APCdg1BxLH.jpg
+var imageFileRe = regexp.MustCompile(`^[^-]+-[A-Z0-9]+-(.+)-[A-Za-z0-9]+\.\w+$`)
+
+// imageView returns the view type of an image, e.g. on_model-standard_view
+func imageView(imagePath string) string {
+	match := imageFileRe.FindStringSubmatch(path.Base(imagePath))
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
+
+func imageURL(imagePath string) string {
+	if imagePath == "" {
+		return ""
+	}
+	return baseURL + imagePath
+}
+
+// listing returns the listing page role of the image, 1, 2 or hover, when it is one of the listing images
+func (names ImageNameData) listing(imagePath string) string {
+	fileName := path.Base(imagePath)
+	for _, name := range []struct{ role, name string }{{"1", names.One}, {"2", names.Two}, {"hover", names.Hover}} {
+		if name.name != "" && strings.Contains(fileName, path.Base(name.name)) {
+			return name.role
+		}
+	}
+	return ""
+}
+
 type ProductResponse struct {
 	Page    Page    `json:"page"`
 	Product Product `json:"product"`
@@ -353,15 +468,42 @@ type ProductPrice struct {
 	DiscountType string      `json:"discountType"`
 }
 
+// ToPrice returns the amounts of the price, the tax rate is the one between them
+func (pp ProductPrice) ToPrice() dto.Price {
+	withTax, ok := dto.ParseYen(pp.Current.WithTax)
+	if !ok && pp.Current.WithTax != "" {
+		slog.Warn("error at parsing price", "with_tax", pp.Current.WithTax)
+	}
+	withoutTax := int(math.Round(pp.Current.WithoutTax))
+
+	taxRate := consumptionTaxRate
+	if withTax > 0 && withoutTax > 0 {
+		taxRate = math.Round((float64(withTax)/float64(withoutTax)-1)*100) / 100
+	}
+
+	return dto.Price{
+		WithTax:           withTax,
+		WithoutTax:        withoutTax,
+		Currency:          currency,
+		TaxRate:           taxRate,
+		TaxIncluded:       true,
+		DisplayWithTax:    pp.Current.WithTax,
+		DisplayWithoutTax: dto.FormatYen(withoutTax),
+		DiscountType:      pp.DiscountType,
+	}
+}
+
 type PriceDetail struct {
 	WithTax    string  `json:"withTax"`
 	WithoutTax float64 `json:"withoutTax"`
 }
 
 type Sku struct {
-	ArticleCode string `json:"articleCode"`
-	SizeName    string `json:"sizeName"`
-	Status      Status `json:"status"`
+	AddToCartButton AddToCartButton `json:"addToCartButton"`
+	ArticleCode     string          `json:"articleCode"` // SKU code
+	PurchaseInfo    PurchaseInfo    `json:"purchaseInfo"`
+	SizeName        string          `json:"sizeName"`
+	Status          Status          `json:"status"`
 }
 
 type AddToCartButton struct {
@@ -1,8 +1,11 @@
 package adidas
 
 import (
+	"errors"
 	"fmt"
-	"log/slog"
+	"math"
+	"slices"
+	"strconv"
 	"strings"
 
 	"vcrawler/internal/dto"
@@ -14,78 +17,181 @@ import (
 
 const (
 	ratingSenseURL = "https://adidasjp.ugc.bazaarvoice.com/7896-ja_jp/%s/reviews.djs?format=embeddedhtml&productattribute_itemKcod=%s"
+
+	// bvRatingSummaryMaterial is the material of the .djs payload holding the rating summary
+	bvRatingSummaryMaterial = "BVRRRatingSummarySourceID"
 )
 
-func GetRatingSense(articleCode, modelCode string) (string, string, []dto.RatingSense) {
+// RatingError tells why the rating data of a product is missing
+type RatingError struct {
+	Reason dto.RatingMissingReason
+	Err    error
+}
+
+func (e *RatingError) Error() string {
+	if e.Err == nil {
+		return string(e.Reason)
+	}
+	return fmt.Sprintf("%s: %v", e.Reason, e.Err)
+}
+
+func (e *RatingError) Unwrap() error {
+	return e.Err
+}
+
+// RatingSummary is the rating of a product on Bazaarvoice
+type RatingSummary struct {
+	Rating             string
+	RecommendedRate    string
+	Senses             []dto.RatingSense
+	AverageRating      float64
+	RecommendedPercent int
+	Histogram          []dto.RatingCount
+}
+
+// GetRatingSense returns the rating summary of the article, or a *RatingError telling why it is missing
+func GetRatingSense(articleCode, modelCode string) (RatingSummary, error) {
 	var (
-		c               *colly.Collector
-		ratingSenses    []dto.RatingSense
-		rating          string
-		recommendedRate string
+		c         *colly.Collector
+		summary   RatingSummary
+		ratingErr error
 	)
 
 	c = helpers.GetCollector()
 	// Handle the response
 	c.OnResponse(func(r *colly.Response) {
-		// Convert the response body to a string
-		responseString := string(r.Body)
+		summary, ratingErr = parseRatingSummary(r.Body)
+	})
+
+	// Handle request errors
+	c.OnError(func(r *colly.Response, err error) {
+		ratingErr = &RatingError{Reason: dto.RatingMissingFetchFailed, Err: err}
+	})
 
-		cleanedString := cleanResponse(responseString)
+	// Start the request
+	if err := c.Visit(fmt.Sprintf(ratingSenseURL, modelCode, articleCode)); err != nil {
+		return summary, &RatingError{Reason: dto.RatingMissingFetchFailed, Err: err}
+	}
 
-		// Load the cleaned string into goquery for scraping
-		doc, err := goquery.NewDocumentFromReader(strings.NewReader(cleanedString))
-		if err != nil {
-			slog.Error("error at loading document", "error", err)
-			return // Return to avoid further processing
-		}
+	// Wait until all asynchronous callbacks are complete
+	c.Wait()
 
-		// Get the rating value
-		// example:  <span itemprop="ratingValue" class="BVRRNumber BVRRRatingNumber">4</span>
-		rating = doc.Find("span[itemprop=ratingValue]").Text()
+	return summary, ratingErr
+}
 
-		// Get the recommanded rate
-		// example: <span class="BVRRBuyAgainPercentage"> <span class="BVRRNumber">86%</span> </span>
-		recommendedRate = doc.Find("span.BVRRBuyAgainPercentage span.BVRRNumber").Text()
+// parseRatingSummary parses the rating summary material of a .djs payload
+func parseRatingSummary(body []byte) (RatingSummary, error) {
+	var summary RatingSummary
 
-		// Scrape the data
-		doc.Find("div.BVRRRatingEntry").Each(func(i int, s *goquery.Selection) {
-			ratingType := s.Find("div.BVRRRatingHeader").Text()
-			ratingValue := s.Find("div.BVRRRatingRadioImage img").AttrOr("alt", "N/A")
+	materials, err := bvMaterials(body)
+	if err != nil {
+		return summary, &RatingError{Reason: dto.RatingMissingMalformedPayload, Err: err}
+	}
 
-			if ratingType != "" || ratingValue != "N/A" {
-				ratingSense := dto.RatingSense{
-					Type:  strings.TrimSpace(ratingType),
-					Value: strings.TrimSpace(ratingValue),
-				}
+	html, ok := materials[bvRatingSummaryMaterial]
+	if !ok {
+		return summary, &RatingError{Reason: dto.RatingMissingNoSummary}
+	}
 
-				ratingSenses = append(ratingSenses, ratingSense)
-			}
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		return summary, &RatingError{Reason: dto.RatingMissingMalformedPayload, Err: err}
+	}
+
+	// Get the rating value
+	// example:  <span itemprop="ratingValue" class="BVRRNumber BVRRRatingNumber">4</span>
+	summary.Rating = text(doc.Find("span[itemprop=ratingValue]").First())
+	summary.AverageRating, _ = strconv.ParseFloat(summary.Rating, 64)
+
+	// Get the recommanded rate
+	// example: <span class="BVRRBuyAgainPercentage"> <span class="BVRRNumber">86%</span> </span>
+	summary.RecommendedRate = text(doc.Find("span.BVRRBuyAgainPercentage span.BVRRNumber").First())
+	summary.RecommendedPercent, _ = strconv.Atoi(strings.TrimSpace(strings.TrimSuffix(summary.RecommendedRate, "%")))
+
+	// Get the star rating histogram
+	// example: <div class="BVRRHistogramBarRow BVRRHistogramBarRow5"> <span class="BVRRHistStarLabelText">5 つ星</span> ... <span class="BVRRHistAbsLabel">11</span> </div>
+	doc.Find("div.BVRRHistogramBarRow").Each(func(i int, s *goquery.Selection) {
+		stars := histogramStars(s)
+		if stars == 0 {
+			return
+		}
+		summary.Histogram = append(summary.Histogram, dto.RatingCount{
+			Stars: stars,
+			Count: count(s.Find(".BVRRHistAbsLabel")),
 		})
 	})
-
-	// Handle request errors
-	c.OnError(func(r *colly.Response, err error) {
-		slog.Error("error at fetching:", "url", r.Request.URL.String(), "error", err)
+	slices.SortFunc(summary.Histogram, func(a, b dto.RatingCount) int { return b.Stars - a.Stars })
+
+	// Scrape the data
+	doc.Find("div.BVRRRatingEntry").Each(func(i int, s *goquery.Selection) {
+		ratingType := text(s.Find("div.BVRRRatingHeader"))
+		ratingValue := strings.TrimSpace(s.Find("div.BVRRRatingRadioImage img").AttrOr("alt", ""))
+
+		if ratingType != "" || ratingValue != "" {
+			summary.Senses = append(summary.Senses, dto.RatingSense{
+				Type:     ratingType,
+				Value:    ratingValue,
+				Position: sliderPosition(ratingValue),
+				// example: <div class="BVRRRatingRadioMinLabel">小さい</div> <div class="BVRRRatingRadioMaxLabel">大きい</div>
+				MinLabel: text(s.Find(`[class*="MinLabel"]`).First()),
+				MaxLabel: text(s.Find(`[class*="MaxLabel"]`).First()),
+			})
+		}
 	})
 
-	// Start the request
-	err := c.Visit(fmt.Sprintf(ratingSenseURL, modelCode, articleCode))
+	if summary.Rating == "" {
+		return summary, &RatingError{Reason: dto.RatingMissingNoRating}
+	}
+	return summary, nil
+}
+
+// sliderPosition returns the position on the 1 to 5 slider of a rating sense value like "2.8 / 5", or 0 when it isn't a position
+func sliderPosition(value string) float64 {
+	position, scale, ok := strings.Cut(value, "/")
+	if !ok {
+		return 0
+	}
+	p, err := strconv.ParseFloat(strings.TrimSpace(position), 64)
 	if err != nil {
-		slog.Error("error at visiting rating sense", "error", err)
-		return rating, recommendedRate, nil
+		return 0
+	}
+	s, err := strconv.ParseFloat(strings.TrimSpace(scale), 64)
+	// A slider needs at least two positions, e.g. "1 / 1" has none to scale from, and starts at 1
+	if err != nil || !finite(p) || !finite(s) || s <= 1 || p < 1 || p > s {
+		return 0
+	}
+	// Scale positions of other sliders to 1 to 5
+	if s != 5 {
+		p = 1 + (p-1)*4/(s-1)
 	}
+	if !finite(p) {
+		return 0
+	}
+	return math.Round(p*10) / 10
+}
 
-	// Wait until all asynchronous callbacks are complete
-	c.Wait()
+// finite tells whether the float is neither NaN nor infinite
+func finite(f float64) bool {
+	return !math.IsNaN(f) && !math.IsInf(f, 0)
+}
 
-	return rating, recommendedRate, ratingSenses
+// histogramStars returns the stars of a histogram row, from its BVRRHistogramBarRow5 class or its label
+func histogramStars(s *goquery.Selection) int {
+	for _, class := range strings.Fields(s.AttrOr("class", "")) {
+		if stars, err := strconv.Atoi(strings.TrimPrefix(class, "BVRRHistogramBarRow")); err == nil {
+			return stars
+		}
+	}
+	label, _, _ := strings.Cut(text(s.Find(".BVRRHistStarLabelText")), " ")
+	stars, _ := strconv.Atoi(strings.TrimRight(label, "つ星"))
+	return stars
 }
 
-// cleanResponse performs cleaning operations on the raw response string
-func cleanResponse(response string) string {
-	response = strings.Split(response, `"BVRRRatingSummarySourceID":"`)[1]
-	response = strings.Split(response, `","BVRRSecondaryRatingSummarySourceID":"`)[0]
-	response = strings.ReplaceAll(response, `\n`, "")
-	response = strings.ReplaceAll(response, `\`, "")
-	return response
+// ratingMissingReason returns the reason of a GetRatingSense error
+func ratingMissingReason(err error) dto.RatingMissingReason {
+	var ratingErr *RatingError
+	if errors.As(err, &ratingErr) {
+		return ratingErr.Reason
+	}
+	return dto.RatingMissingFetchFailed
 }
@@ -0,0 +1,94 @@
+package adidas
+
+import (
+	"testing"
+
+	"vcrawler/internal/dto"
+)
+
+func TestParseRatingSummaryMissingReason(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		reason dto.RatingMissingReason
+	}{
+		{
+			name:   "empty body",
+			body:   ``,
+			reason: dto.RatingMissingMalformedPayload,
+		},
+		{
+			name:   "no materials",
+			body:   `var materials = {};`,
+			reason: dto.RatingMissingMalformedPayload,
+		},
+		{
+			name:   "truncated object",
+			body:   `var materials={"BVRRRatingSummarySourceID":"<div class=\"BVRRRating`,
+			reason: dto.RatingMissingMalformedPayload,
+		},
+		{
+			name:   "missing summary",
+			body:   `var materials={"BVRRSecondaryRatingSummarySourceID":"<div></div>"}; BV.load();`,
+			reason: dto.RatingMissingNoSummary,
+		},
+		{
+			name:   "missing rating",
+			body:   `var materials={"BVRRRatingSummarySourceID":"<span class=\"BVRRBuyAgainPercentage\"><span class=\"BVRRNumber\">86%</span></span>"}; BV.load();`,
+			reason: dto.RatingMissingNoRating,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseRatingSummary([]byte(tt.body))
+			if err == nil {
+				t.Fatalf("parseRatingSummary() error = nil, want %s", tt.reason)
+			}
+			if got := ratingMissingReason(err); got != tt.reason {
+				t.Errorf("ratingMissingReason() = %s, want %s (error: %v)", got, tt.reason, err)
+			}
+		})
+	}
+}
+
+func TestParseRatingSummary(t *testing.T) {
+	body := `var materials={"BVRRRatingSummarySourceID":"<span itemprop=\"ratingValue\" class=\"BVRRNumber BVRRRatingNumber\">4.2</span>` +
+		`<span class=\"BVRRBuyAgainPercentage\"> <span class=\"BVRRNumber\">86%</span> </span>"}; BV.load();`
+
+	summary, err := parseRatingSummary([]byte(body))
+	if err != nil {
+		t.Fatalf("parseRatingSummary() error = %v", err)
+	}
+	if summary.Rating != "4.2" || summary.AverageRating != 4.2 {
+		t.Errorf("rating = %q (%v), want 4.2", summary.Rating, summary.AverageRating)
+	}
+	if summary.RecommendedPercent != 86 {
+		t.Errorf("recommended percent = %d, want 86", summary.RecommendedPercent)
+	}
+}
+
+func TestSliderPosition(t *testing.T) {
+	tests := []struct {
+		value string
+		want  float64
+	}{
+		{"2.8 / 5", 2.8},
+		{"2 / 3", 3},
+		{"1/1", 0},
+		{"0/0", 0},
+		{"0 / 3", 0},
+		{"0 / 5", 0},
+		{"1 / 3", 1},
+		{"NaN / 5", 0},
+		{"1 / Inf", 0},
+		{"6 / 5", 0},
+		{"小さい", 0},
+	}
+
+	for _, tt := range tests {
+		if got := sliderPosition(tt.value); got != tt.want {
+			t.Errorf("sliderPosition(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
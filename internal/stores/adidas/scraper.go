@@ -4,6 +4,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
 	"sync/atomic"
 
 	"vcrawler/internal/dto"
@@ -14,6 +17,30 @@ import (
 )
 
 type scraper struct {
+	options   Options
+	failures  []dto.Failure
+	onProduct func(product dto.Product)
+	articles  map[string]Article // Articles of the listing pages by article code
+}
+
+func (s *scraper) Name() string {
+	return storeName
+}
+
+func (s *scraper) GetFailures() []dto.Failure {
+	return s.failures
+}
+
+func (s *scraper) ResetFailures() {
+	s.failures = nil
+}
+
+func (s *scraper) OnProduct(callback func(product dto.Product)) {
+	s.onProduct = callback
+}
+
+func (s *scraper) addFailure(url string, err error) {
+	s.failures = append(s.failures, dto.Failure{URL: url, Cause: err.Error()})
 }
 
 func (s *scraper) GetProductsURL(dumpLimit int) ([]string, error) {
@@ -35,10 +62,14 @@ func (s *scraper) GetProductsURL(dumpLimit int) ([]string, error) {
 		// Unmarshal JSON into Go struct
 		if err := json.Unmarshal(r.Body, &plr); err != nil {
 			slog.Error("error at unmarshalling json", "error", err)
+			s.addFailure(r.Request.URL.String(), err)
 			return
 		}
 
 		productURLs = append(productURLs, plr.URLList()...)
+		for _, article := range plr.Articles {
+			s.articles[article.Article] = article
+		}
 		pageTotal = plr.SearchOptions.PageTotal
 		currentPage = plr.CurrentPage()
 
@@ -62,6 +93,7 @@ func (s *scraper) GetProductsURL(dumpLimit int) ([]string, error) {
 	// Handle request errors
 	c.OnError(func(r *colly.Response, err error) {
 		slog.Error("error at fetching:", "url", r.Request.URL.String(), "error", err)
+		s.addFailure(r.Request.URL.String(), err)
 	})
 
 	// Start the request
@@ -76,6 +108,66 @@ func (s *scraper) GetProductsURL(dumpLimit int) ([]string, error) {
 	return productURLs, nil
 }
 
+func (s *scraper) GetModelArticles(modelCodes []string) (map[string][]string, error) {
+	var (
+		c        *colly.Collector
+		articles = map[string][]string{}
+	)
+
+	c = helpers.GetCollector()
+
+	c.OnRequest(func(r *colly.Request) {
+		r.Headers.Set("User-Agent", helpers.GetRandomUserAgent())
+		slog.Info("searching colorways", "url", r.URL.String())
+	})
+
+	c.OnResponse(func(r *colly.Response) {
+		var plr ProductListResponse
+		if err := json.Unmarshal(r.Body, &plr); err != nil {
+			slog.Error("error at unmarshalling json", "error", err)
+			s.addFailure(r.Request.URL.String(), err)
+			return
+		}
+
+		modelCode := r.Ctx.Get("model_code")
+		for _, article := range plr.Articles {
+			if article.ModelCode != modelCode {
+				continue
+			}
+			s.articles[article.Article] = article
+			articles[modelCode] = append(articles[modelCode], article.Article)
+		}
+	})
+
+	c.OnError(func(r *colly.Response, err error) {
+		slog.Error("error at fetching:", "url", r.Request.URL.String(), "error", err)
+		s.addFailure(r.Request.URL.String(), err)
+	})
+
+	for _, modelCode := range modelCodes {
+		ctx := colly.NewContext()
+		ctx.Put("model_code", modelCode)
+		if err := c.Request(http.MethodGet, fmt.Sprintf(modelSearchURLfmt, url.QueryEscape(modelCode)), nil, ctx, nil); err != nil {
+			slog.Error("error at searching colorways", "model", modelCode, "error", err)
+		}
+	}
+
+	c.Wait()
+
+	for _, codes := range articles {
+		slices.Sort(codes)
+	}
+	return articles, nil
+}
+
+func (s *scraper) GetArticlesURL(articleCodes []string) []string {
+	urls := make([]string, 0, len(articleCodes))
+	for _, articleCode := range articleCodes {
+		urls = append(urls, fmt.Sprintf(baseApiURLfmt, articleCode))
+	}
+	return urls
+}
+
 func (s *scraper) GetProductsDetail(productsURL []string) ([]dto.Product, error) {
 	var (
 		c         *colly.Collector
@@ -98,11 +190,26 @@ func (s *scraper) GetProductsDetail(productsURL []string) ([]dto.Product, error)
 		// Unmarshal JSON into Go struct
 		if err := json.Unmarshal(r.Body, &pr); err != nil {
 			slog.Error("error at unmarshalling json", "error", err)
+			s.addFailure(r.Request.URL.String(), err)
 			return
 		}
 
-		product := pr.ToProduct()
+		product := pr.ToProduct(s.articles[pr.Product.Article.ArticleCode])
+		if s.options.MaxReviews > 0 && pr.Product.Model.Review.ReviewCount > 0 {
+			reviews, err := GetReviews(product.ArticleCode, product.ModelCode, s.options.MaxReviews)
+			if err != nil {
+				slog.Error("error at fetching reviews", "article", product.ArticleCode, "error", err)
+				s.addFailure(fmt.Sprintf(reviewsURL, product.ModelCode, product.ArticleCode, 1), err)
+			}
+			// The reviews of the product API are kept when no page could be crawled
+			if len(reviews) > 0 {
+				product.Reviews = reviews
+			}
+		}
 		products = append(products, product)
+		if s.onProduct != nil {
+			s.onProduct(product)
+		}
 
 		// Increment the counter and display progress
 		completedCount := atomic.AddInt64(&completed, 1)
@@ -114,6 +221,7 @@ func (s *scraper) GetProductsDetail(productsURL []string) ([]dto.Product, error)
 	// Handle request errors
 	c.OnError(func(r *colly.Response, err error) {
 		slog.Error("error at fetching:", "url", r.Request.URL.String(), "error", err)
+		s.addFailure(r.Request.URL.String(), err)
 
 		// Still increment the counter for errors to avoid progress being stuck
 		completedCount := atomic.AddInt64(&completed, 1)
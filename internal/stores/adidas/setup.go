@@ -3,11 +3,24 @@ package adidas
 import "vcrawler/internal/definition"
 
 const (
+	storeName     = "adidas"
 	baseURL       = "https://shop.adidas.jp"
 	baseApiURLfmt = "https://shop.adidas.jp/f/v2/web/pub/products/article/%s/"
-	listingURLfmt = "https://shop.adidas.jp/f/v1/pub/product/list?category=wear&gender=mens&limit=120&order=10&page=%d"
+	currency      = "JPY"
+	// consumptionTaxRate is the Japanese consumption tax rate, included in the displayed prices
+	consumptionTaxRate = 0.1
+	listingURLfmt      = "https://shop.adidas.jp/f/v1/pub/product/list?category=wear&gender=mens&limit=120&order=10&page=%d"
+	// modelSearchURLfmt searches the listing by model code, the results may also hold articles of other models
+	modelSearchURLfmt = "https://shop.adidas.jp/f/v1/pub/product/list?q=%s&limit=120&page=1"
 )
 
-func GetAdidasStore() definition.Store {
-	return &scraper{}
+// DefaultMaxReviews is the maximum number of reviews crawled per product
+const DefaultMaxReviews = 100
+
+type Options struct {
+	MaxReviews int // Reviews crawled from Bazaarvoice per product, only the reviews of the product API when 0
+}
+
+func GetAdidasStore(options Options) definition.Store {
+	return &scraper{options: options, articles: map[string]Article{}}
 }
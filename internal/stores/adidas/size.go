@@ -0,0 +1,155 @@
+package adidas
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"vcrawler/internal/dto"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// SizeContext tells how to read the numeric sizes of a product
+type SizeContext struct {
+	Kids  bool // Numeric sizes are kids heights in cm, e.g. 120
+	Shoes bool // Numeric sizes are JP shoe sizes in cm, e.g. 26.5
+}
+
+// letterSizes are the apparel letter sizes in the canonical size order
+var letterSizes = []string{"4XS", "3XS", "2XS", "XS", "S", "M", "L", "XL", "2XL", "3XL", "4XL", "5XL", "6XL"}
+
+// letterSizeAliases maps the other spellings of the letter sizes to their canonical code
+var letterSizeAliases = map[string]string{
+	"XXXXS": "4XS",
+	"XXXS":  "3XS",
+	"XXS":   "2XS",
+	"XXL":   "2XL",
+	"XXXL":  "3XL",
+	"XXXXL": "4XL",
+	// JP letter sizes, O (大きい) is the XL
+	"O":   "XL",
+	"XO":  "2XL",
+	"2XO": "3XL",
+	"LL":  "XL",
+	"3L":  "2XL",
+	"4L":  "3XL",
+}
+
+// oneSizes are the labels of the articles with a single size
+var oneSizes = []string{"FREE", "F", "NS", "OSFM", "OSFW", "OSFY", "OSFC", "ワンサイズ", "フリー"}
+
+// NormalizeSize returns the canonical size of a size label of the SKUs or the size charts,
+// e.g. 2XL for XXL and J/2XL, or 26.5 in the JP shoe system for 26.5cm
+func NormalizeSize(label string, context SizeContext) dto.Size {
+	label = strings.ToUpper(strings.ReplaceAll(norm.NFKC.String(label), " ", ""))
+	if label == "" {
+		return dto.Size{}
+	}
+
+	label, variant := cutSizeVariant(label)
+	variant = strings.ToLower(variant)
+	// J/ is the Japanese sizing of the global sizes
+	label = strings.TrimPrefix(label, "J/")
+
+	if slices.Contains(oneSizes, label) {
+		return dto.Size{Code: "ONE", System: dto.SizeSystemOneSize, Variant: variant}
+	}
+
+	if code, ok := letterSize(label); ok {
+		return dto.Size{Code: code, System: dto.SizeSystemApparelLetter, Variant: variant}
+	}
+	// Letter ranges, e.g. M-L or J/O-J/XO
+	if lower, upper, ok := strings.Cut(label, "-"); ok {
+		lowerCode, lowerOk := letterSize(lower)
+		upperCode, upperOk := letterSize(strings.TrimPrefix(upper, "J/"))
+		if lowerOk && upperOk {
+			if slices.Index(letterSizes, lowerCode) > slices.Index(letterSizes, upperCode) {
+				lowerCode, upperCode = upperCode, lowerCode
+			}
+			return dto.Size{Code: lowerCode + "-" + upperCode, System: dto.SizeSystemApparelLetter, Variant: variant}
+		}
+	}
+	// T is the tall fit of the letter sizes, e.g. 2XLT
+	if code, ok := letterSize(strings.TrimSuffix(label, "T")); ok && strings.HasSuffix(label, "T") {
+		return dto.Size{Code: code, System: dto.SizeSystemApparelLetter, Variant: strings.Trim("tall "+variant, " ")}
+	}
+
+	// Numeric sizes may be ranges, e.g. 22.5-23.0 or 120-130cm
+	number, cm := strings.CutSuffix(label, "CM")
+	lower, upper, isRange := strings.Cut(number, "-")
+	lower, lowerCm := strings.CutSuffix(lower, "CM")
+	cm = cm || lowerCm
+	n, err := strconv.ParseFloat(lower, 64)
+	if err != nil {
+		return dto.Size{Code: label, Variant: variant}
+	}
+	m := n
+	if isRange {
+		if m, err = strconv.ParseFloat(upper, 64); err != nil {
+			return dto.Size{Code: label, Variant: variant}
+		}
+		n, m = min(n, m), max(n, m)
+	}
+
+	var (
+		system = dto.SizeSystemNumeric
+		format = func(f float64) string { return strconv.FormatFloat(f, 'f', -1, 64) }
+	)
+	switch {
+	case context.Kids && n >= 50:
+		system = dto.SizeSystemKids
+	case (cm || context.Shoes) && n >= 10 && m <= 35:
+		system = dto.SizeSystemJPShoe
+		format = func(f float64) string { return fmt.Sprintf("%.1f", f) }
+	}
+	code := format(n)
+	if isRange {
+		code += "-" + format(m)
+	}
+	return dto.Size{Code: code, System: system, Variant: variant}
+}
+
+// cutSizeVariant cuts the variant off a size label, e.g. 7INCH丈 of S-7INCH丈.
+// The upper bound of a range like 22.5-23.0 or M-L is kept in the label, it isn't a variant.
+func cutSizeVariant(label string) (string, string) {
+	label, variant, ok := strings.Cut(label, "-")
+	if !ok {
+		return label, ""
+	}
+	upper, rest, _ := strings.Cut(variant, "-")
+	if _, err := strconv.ParseFloat(strings.TrimSuffix(upper, "CM"), 64); err == nil {
+		return label + "-" + upper, rest
+	}
+	if _, ok := letterSize(strings.TrimPrefix(upper, "J/")); ok {
+		return label + "-" + upper, rest
+	}
+	return label, variant
+}
+
+// letterSize returns the canonical code of a letter size
+func letterSize(label string) (string, bool) {
+	if code, ok := letterSizeAliases[label]; ok {
+		return code, true
+	}
+	return label, slices.Contains(letterSizes, label)
+}
+
+// sizeRank returns the position of the size in the canonical size order, one sizes and letter sizes before numeric sizes
+func sizeRank(size dto.Size) (float64, bool) {
+	switch size.System {
+	case dto.SizeSystemOneSize:
+		return 0, true
+	case dto.SizeSystemApparelLetter:
+		// Ranges like M-L rank by their lower bound
+		lower, _, _ := strings.Cut(size.Code, "-")
+		return float64(1 + slices.Index(letterSizes, lower)), true
+	case dto.SizeSystemJPShoe, dto.SizeSystemKids, dto.SizeSystemNumeric:
+		// Ranges like 22.5-23.0 rank by their lower bound
+		lower, _, _ := strings.Cut(size.Code, "-")
+		n, err := strconv.ParseFloat(lower, 64)
+		return float64(1+len(letterSizes)) + n, err == nil
+	}
+	return 0, false
+}
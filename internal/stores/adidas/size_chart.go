@@ -1,9 +1,14 @@
 package adidas
 
 import (
+	"cmp"
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
 
 	"vcrawler/internal/dto"
 	"vcrawler/pkg/helpers"
@@ -53,35 +58,108 @@ func GetSizeCharts(modelCode string) []dto.SizeChart {
 	return sizeCharts
 }
 
+// getSizeCharts returns the rows of every section of the size chart, in the canonical size order
 func (scr SizeChartResponse) getSizeCharts() []dto.SizeChart {
 	var sizeCharts []dto.SizeChart
 
-	for _, sizeData := range scr.SizeChart {
-		for _, body := range sizeData.Body {
+	for _, section := range sortedKeys(scr.SizeChart) {
+		sizeData := scr.SizeChart[section]
+		headers := sizeData.headers()
+
+		var rows []dto.SizeChart
+		for _, row := range sortedKeys(sizeData.Body) {
+			body := sizeData.Body[row]
 			var measurements []dto.Measurement
 
-			for bi, v := range body {
-				m := dto.Measurement{
-					Type:  scr.SizeChart["0"].Header["0"][bi].Value,
-					Value: v.Value,
+			for _, column := range sortedKeys(body) {
+				// The first column is the size, its header is empty or 表示サイズ
+				if column == "0" {
+					continue
 				}
+				m := toMeasurement(headers[column], strings.TrimSpace(body[column].Value))
 
-				if m.Type != "" {
+				if m.Type != "" && m.Value != "" {
 					measurements = append(measurements, m)
 				}
 			}
 
 			sizeChart := dto.SizeChart{
-				Size:         body["0"].Value,
+				Section:      section,
+				Size:         strings.TrimSpace(body["0"].Value),
+				ActualSize:   sizeData.HasActualSize,
+				Exact:        scr.IsExactFlag == 1,
 				Measurements: measurements,
 			}
-			sizeCharts = append(sizeCharts, sizeChart)
+			if sizeChart.Size != "" {
+				rows = append(rows, sizeChart)
+			}
 		}
+
+		sortSizeCharts(rows)
+		sizeCharts = append(sizeCharts, rows...)
 	}
+	fillChartUnit(sizeCharts)
 
 	return sizeCharts
 }
 
+// headers returns the header of each column of the section, the values of multi-row headers are joined
+func (scd SizeChartData) headers() map[string]string {
+	headers := map[string]string{}
+	for _, row := range sortedKeys(scd.Header) {
+		for column, header := range scd.Header[row] {
+			value := strings.TrimSpace(header.Value)
+			if value == "" || value == headers[column] {
+				continue
+			}
+			headers[column] = strings.TrimSpace(headers[column] + " " + value)
+		}
+	}
+	return headers
+}
+
+// sortedKeys returns the "0", "1", ... keys of the size chart maps in their numeric order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := slices.Collect(maps.Keys(m))
+	slices.SortFunc(keys, func(a, b string) int {
+		i, errA := strconv.Atoi(a)
+		j, errB := strconv.Atoi(b)
+		if errA != nil || errB != nil {
+			return strings.Compare(a, b)
+		}
+		return i - j
+	})
+	return keys
+}
+
+// sortSizeCharts sorts the rows of a section by variant, in the order they come, then in the canonical size order.
+// The sizes that can't be ranked keep their place after the ranked sizes of their variant.
+func sortSizeCharts(rows []dto.SizeChart) {
+	variants := map[string]int{}
+	for _, row := range rows {
+		variant := NormalizeSize(row.Size, SizeContext{}).Variant
+		if _, ok := variants[variant]; !ok {
+			variants[variant] = len(variants)
+		}
+	}
+
+	slices.SortStableFunc(rows, func(a, b dto.SizeChart) int {
+		sizeA, sizeB := NormalizeSize(a.Size, SizeContext{}), NormalizeSize(b.Size, SizeContext{})
+		rankA, okA := sizeRank(sizeA)
+		rankB, okB := sizeRank(sizeB)
+		if c := cmp.Compare(variants[sizeA.Variant], variants[sizeB.Variant]); c != 0 {
+			return c
+		}
+		if okA != okB {
+			if okA {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Compare(rankA, rankB)
+	})
+}
+
 type SizeImage struct {
 	Path string `json:"path"`
 }
@@ -94,27 +172,13 @@ type SizeHeader struct {
 	Value string `json:"value"`
 }
 
-type SizeBody struct {
-	Value string `json:"value"`
-}
-
-type Body struct {
-	XS   SizeBody `json:"0"`
-	S    SizeBody `json:"1"`
-	M    SizeBody `json:"2"`
-	L    SizeBody `json:"3"`
-	XL   SizeBody `json:"4"`
-	XXL  SizeBody `json:"5"`
-	XXXL SizeBody `json:"6"`
-}
-
 type SizeChartData struct {
-	Body          map[string]map[string]SizeValue  `json:"body"`
-	HasActualSize bool                             `json:"has_actual_size"`
-	Header        map[string]map[string]SizeHeader `json:"header"`
+	Body          map[string]map[string]SizeValue  `json:"body"`            // Rows by index, of the cells by column index
+	HasActualSize bool                             `json:"has_actual_size"` // The rows are the measurements of the garment, not of the body
+	Header        map[string]map[string]SizeHeader `json:"header"`          // Header rows by index, of the cells by column index
 }
 
 type SizeChartResponse struct {
-	IsExactFlag int                      `json:"is_exact_flag"`
+	IsExactFlag int                      `json:"is_exact_flag"` // 1 when the chart is the chart of the model, 0 for the reference chart of its category
 	SizeChart   map[string]SizeChartData `json:"size_chart"`
 }
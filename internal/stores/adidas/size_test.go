@@ -0,0 +1,62 @@
+package adidas
+
+import (
+	"testing"
+
+	"vcrawler/internal/dto"
+)
+
+func TestNormalizeSize(t *testing.T) {
+	tests := []struct {
+		label   string
+		context SizeContext
+		want    dto.Size
+	}{
+		{"J/XXL", SizeContext{}, dto.Size{Code: "2XL", System: dto.SizeSystemApparelLetter}},
+		{"S-7INCH丈", SizeContext{}, dto.Size{Code: "S", System: dto.SizeSystemApparelLetter, Variant: "7inch丈"}},
+		{"26.5cm", SizeContext{}, dto.Size{Code: "26.5", System: dto.SizeSystemJPShoe}},
+		{"22.5-23.0", SizeContext{Shoes: true}, dto.Size{Code: "22.5-23.0", System: dto.SizeSystemJPShoe}},
+		{"22.5cm-23.0cm", SizeContext{}, dto.Size{Code: "22.5-23.0", System: dto.SizeSystemJPShoe}},
+		{"120-130-ロング", SizeContext{Kids: true}, dto.Size{Code: "120-130", System: dto.SizeSystemKids, Variant: "ロング"}},
+		{"フリー", SizeContext{}, dto.Size{Code: "ONE", System: dto.SizeSystemOneSize}},
+		{"J/O", SizeContext{}, dto.Size{Code: "XL", System: dto.SizeSystemApparelLetter}},
+		{"J/XO", SizeContext{}, dto.Size{Code: "2XL", System: dto.SizeSystemApparelLetter}},
+		{"J/2XO", SizeContext{}, dto.Size{Code: "3XL", System: dto.SizeSystemApparelLetter}},
+		{"LL", SizeContext{}, dto.Size{Code: "XL", System: dto.SizeSystemApparelLetter}},
+		{"M-L", SizeContext{}, dto.Size{Code: "M-L", System: dto.SizeSystemApparelLetter}},
+		{"2XL-3XL", SizeContext{}, dto.Size{Code: "2XL-3XL", System: dto.SizeSystemApparelLetter}},
+		{"J/O-J/XO", SizeContext{}, dto.Size{Code: "XL-2XL", System: dto.SizeSystemApparelLetter}},
+		{"XXL-XXXL", SizeContext{}, dto.Size{Code: "2XL-3XL", System: dto.SizeSystemApparelLetter}},
+		{"M-L-ロング", SizeContext{}, dto.Size{Code: "M-L", System: dto.SizeSystemApparelLetter, Variant: "ロング"}},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeSize(tt.label, tt.context); got != tt.want {
+			t.Errorf("NormalizeSize(%q) = %+v, want %+v", tt.label, got, tt.want)
+		}
+	}
+}
+
+func TestSortSizeChartsRanges(t *testing.T) {
+	rows := []dto.SizeChart{{Size: "24.5-25.0"}, {Size: "22.5-23.0"}, {Size: "23.5-24.0"}}
+	sortSizeCharts(rows)
+
+	want := []string{"22.5-23.0", "23.5-24.0", "24.5-25.0"}
+	for i, row := range rows {
+		if row.Size != want[i] {
+			t.Fatalf("sortSizeCharts() = %v, want %v", rows, want)
+		}
+	}
+}
+
+func TestSortSizeChartsLetterRanges(t *testing.T) {
+	rows := []dto.SizeChart{{Size: "J/XO"}, {Size: "2XL-3XL"}, {Size: "M-L"}, {Size: "J/O"}, {Size: "S"}}
+	sortSizeCharts(rows)
+
+	want := []string{"S", "M-L", "J/O", "J/XO", "2XL-3XL"}
+	for i, row := range rows {
+		if row.Size != want[i] {
+			t.Fatalf("sortSizeCharts() = %v, want %v", rows, want)
+		}
+	}
+}
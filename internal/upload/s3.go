@@ -0,0 +1,215 @@
+package upload
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"vcrawler/internal/definition"
+	"vcrawler/internal/dto"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// defaultPartSize is the size of the parts of the multipart uploads, smaller files are uploaded at once
+const defaultPartSize = 16 << 20
+
+type S3Options struct {
+	Endpoint  string // host[:port] of the S3-compatible service, e.g. s3.amazonaws.com or localhost:9000
+	Bucket    string
+	Region    string
+	AccessKey string
+	SecretKey string
+	Insecure  bool   // Uses http instead of https, for a local MinIO
+	PartSize  uint64 // Size of the multipart upload parts
+}
+
+type s3Uploader struct {
+	options     S3Options
+	client      *minio.Client
+	bucketReady bool // The bucket exists or was created
+}
+
+// GetS3Uploader returns an uploader that uploads the files of a run, e.g. the outputs,
+// the run report and the failure manifest, under the store/date/run-id/ prefix of the bucket
+func GetS3Uploader(options S3Options) (definition.Uploader, error) {
+	if options.PartSize == 0 {
+		options.PartSize = defaultPartSize
+	}
+
+	client, err := minio.New(options.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(options.AccessKey, options.SecretKey, ""),
+		Secure: !options.Insecure,
+		Region: options.Region,
+		// Sends the SHA-256 checksums of the uploads as trailing headers
+		TrailingHeaders: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &s3Uploader{options: options, client: client}, nil
+}
+
+func (u *s3Uploader) Upload(report *dto.RunReport, files []string) error {
+	ctx := context.Background()
+
+	if err := u.ensureBucket(ctx); err != nil {
+		return err
+	}
+
+	prefix := path.Join(report.Store, report.StartedAt.Format("2006-01-02"), report.RunID)
+
+	for _, file := range files {
+		// Outputs also hold remote locations, like the search index, only the local files are uploaded
+		if info, err := os.Stat(file); err != nil || info.IsDir() {
+			continue
+		}
+
+		key := path.Join(prefix, filepath.Base(file))
+		if err := u.upload(ctx, file, key); err != nil {
+			return fmt.Errorf("error at uploading %s: %w", file, err)
+		}
+
+		location := fmt.Sprintf("s3://%s/%s", u.options.Bucket, key)
+		report.Uploads = append(report.Uploads, location)
+		slog.Info("file uploaded to", "file", file, "location", location)
+	}
+
+	return nil
+}
+
+// ensureBucket creates the bucket when it doesn't exist
+func (u *s3Uploader) ensureBucket(ctx context.Context) error {
+	if u.bucketReady {
+		return nil
+	}
+
+	exists, err := u.client.BucketExists(ctx, u.options.Bucket)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		if err := u.client.MakeBucket(ctx, u.options.Bucket, minio.MakeBucketOptions{Region: u.options.Region}); err != nil {
+			return err
+		}
+	}
+
+	u.bucketReady = true
+	return nil
+}
+
+// upload uploads the file, in parts when it is larger than the part size, with its SHA-256 checksum,
+// and verifies the checksum the service stored for the object against the local file
+func (u *s3Uploader) upload(ctx context.Context, file, key string) error {
+	checksum, err := fileChecksum(file, u.options.PartSize)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	_, err = u.client.PutObject(ctx, u.options.Bucket, key, f, info.Size(), minio.PutObjectOptions{
+		ContentType:  contentType(file),
+		PartSize:     u.options.PartSize,
+		Checksum:     minio.ChecksumSHA256,
+		UserMetadata: map[string]string{"sha256": checksum.sha256},
+	})
+	if err != nil {
+		return err
+	}
+
+	obj, err := u.client.StatObject(ctx, u.options.Bucket, key, minio.StatObjectOptions{Checksum: true})
+	if err != nil {
+		return err
+	}
+
+	// Some services leave the -<parts> suffix out of the checksum of multipart objects
+	uploaded, _, _ := strings.Cut(obj.ChecksumSHA256, "-")
+	if local, _, _ := strings.Cut(checksum.object, "-"); uploaded != local {
+		return fmt.Errorf("checksum mismatch of s3://%s/%s: local %s, uploaded %s", u.options.Bucket, key, checksum.object, obj.ChecksumSHA256)
+	}
+
+	return nil
+}
+
+// checksums are the SHA-256 checksums of a local file
+type checksums struct {
+	sha256 string // Hex SHA-256 of the content
+	object string // x-amz-checksum-sha256 of the object, the checksum of the part checksums for multipart uploads
+}
+
+// fileChecksum returns the checksums of the file, uploaded in parts of partSize when it is larger than partSize
+func fileChecksum(file string, partSize uint64) (checksums, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return checksums{}, err
+	}
+	defer f.Close()
+
+	var (
+		content = sha256.New()
+		parts   = sha256.New()
+		count   int
+		part    []byte
+	)
+	for {
+		h := sha256.New()
+		n, err := io.Copy(io.MultiWriter(h, content), io.LimitReader(f, int64(partSize)))
+		if err != nil {
+			return checksums{}, err
+		}
+		if n == 0 && count > 0 {
+			break
+		}
+		part = h.Sum(nil)
+		parts.Write(part)
+		count++
+		if n < int64(partSize) {
+			break
+		}
+	}
+
+	c := checksums{sha256: hex.EncodeToString(content.Sum(nil))}
+	if count == 1 {
+		c.object = base64.StdEncoding.EncodeToString(part)
+	} else {
+		c.object = fmt.Sprintf("%s-%d", base64.StdEncoding.EncodeToString(parts.Sum(nil)), count)
+	}
+	return c, nil
+}
+
+func contentType(file string) string {
+	switch filepath.Ext(file) {
+	case ".csv":
+		return "text/csv"
+	case ".ndjson":
+		return "application/x-ndjson"
+	case ".parquet":
+		return "application/vnd.apache.parquet"
+	}
+
+	if t := mime.TypeByExtension(filepath.Ext(file)); t != "" {
+		return t
+	}
+	return "application/octet-stream"
+}
@@ -0,0 +1,116 @@
+package upload
+
+import (
+	"bytes"
+	"cmp"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"vcrawler/internal/dto"
+
+	"github.com/minio/minio-go/v7"
+)
+
+// testPartSize is the smallest part size S3 accepts
+const testPartSize = 5 << 20
+
+func writeTestFile(t *testing.T, name string, size int) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), name)
+	data := bytes.Repeat([]byte("vcrawler"), size/8+1)[:size]
+	if err := os.WriteFile(file, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestFileChecksum(t *testing.T) {
+	tests := []struct {
+		name  string
+		size  int
+		parts int
+	}{
+		{"empty", 0, 1},
+		{"single part", 1 << 10, 1},
+		{"exactly one part", testPartSize, 1},
+		{"multipart", 2*testPartSize + 1, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := writeTestFile(t, "products.csv", tt.size)
+			data, _ := os.ReadFile(file)
+
+			got, err := fileChecksum(file, testPartSize)
+			if err != nil {
+				t.Fatalf("fileChecksum() error = %v", err)
+			}
+			if want := fmt.Sprintf("%x", sha256.Sum256(data)); got.sha256 != want {
+				t.Errorf("sha256 = %s, want %s", got.sha256, want)
+			}
+
+			// The object checksum is the one S3 computes from the part checksums
+			var parts []minio.ObjectPart
+			for i := 0; i == 0 || i*testPartSize < len(data); i++ {
+				sum := sha256.Sum256(data[i*testPartSize : min((i+1)*testPartSize, len(data))])
+				parts = append(parts, minio.ObjectPart{PartNumber: i + 1, ChecksumSHA256: base64.StdEncoding.EncodeToString(sum[:])})
+			}
+			want := parts[0].ChecksumSHA256
+			if tt.parts > 1 {
+				composite, err := minio.ChecksumSHA256.CompositeChecksum(parts)
+				if err != nil {
+					t.Fatal(err)
+				}
+				want = fmt.Sprintf("%s-%d", composite.Encoded(), tt.parts)
+			}
+			if got.object != want {
+				t.Errorf("object checksum = %s, want %s", got.object, want)
+			}
+		})
+	}
+}
+
+// TestS3UploaderMinIO uploads to the MinIO at $S3_TEST_ENDPOINT, e.g. localhost:9000 for
+// docker run -p 9000:9000 minio/minio server /data
+func TestS3UploaderMinIO(t *testing.T) {
+	endpoint := os.Getenv("S3_TEST_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("S3_TEST_ENDPOINT is not set")
+	}
+
+	uploader, err := GetS3Uploader(S3Options{
+		Endpoint:  endpoint,
+		Bucket:    "vcrawler-test",
+		AccessKey: cmp.Or(os.Getenv("S3_TEST_ACCESS_KEY"), "minioadmin"),
+		SecretKey: cmp.Or(os.Getenv("S3_TEST_SECRET_KEY"), "minioadmin"),
+		Insecure:  true,
+		PartSize:  testPartSize,
+	})
+	if err != nil {
+		t.Fatalf("GetS3Uploader() error = %v", err)
+	}
+
+	report := &dto.RunReport{Store: "adidas", RunID: fmt.Sprintf("test-%d", time.Now().UnixNano()), StartedAt: time.Now()}
+	files := []string{
+		writeTestFile(t, "products.csv", 1<<10),
+		writeTestFile(t, "products.parquet", 2*testPartSize+1),
+	}
+	if err := uploader.Upload(report, files); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+
+	if len(report.Uploads) != len(files) {
+		t.Fatalf("uploads = %v, want %d locations", report.Uploads, len(files))
+	}
+	for i, location := range report.Uploads {
+		if !strings.HasSuffix(location, "/"+report.RunID+"/"+filepath.Base(files[i])) {
+			t.Errorf("location = %s, want the run prefix and %s", location, filepath.Base(files[i]))
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package watch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"vcrawler/internal/definition"
+	"vcrawler/internal/dto"
+)
+
+const (
+	DefaultInterval  = 10 * time.Minute
+	DefaultCooldown  = time.Hour
+	DefaultStateFile = "watch.state.json"
+)
+
+type Options struct {
+	Articles  []string             // Article codes to watch
+	Interval  time.Duration        // Delay between two crawls of the articles
+	Cooldown  time.Duration        // Suppresses an alert of a size alerted with the same kind within the cooldown
+	StateFile string               // Keeps the last seen availability across restarts, in memory only when empty
+	Once      bool                 // Crawls the articles a single time
+	Alerters  []definition.Alerter // Receive the alerts
+}
+
+// sizeState is the last seen online availability of a size, and the time of its last alert of each kind
+type sizeState struct {
+	InStock   bool                             `json:"in_stock"`
+	AlertedAt map[dto.StockAlertKind]time.Time `json:"alerted_at,omitempty"`
+}
+
+// state is the sizeState of each size of each watched article
+type state map[string]map[string]*sizeState
+
+type watcher struct {
+	options Options
+	state   state
+}
+
+func GetWatcher(options Options) definition.Watcher {
+	if options.Interval <= 0 {
+		options.Interval = DefaultInterval
+	}
+
+	return &watcher{options: options, state: state{}}
+}
+
+func (w *watcher) Watch(ctx context.Context, store definition.Store) error {
+	if len(w.options.Articles) == 0 {
+		return errors.New("no articles to watch")
+	}
+
+	if err := w.load(); err != nil {
+		return err
+	}
+
+	urls := store.GetArticlesURL(w.options.Articles)
+	for {
+		slog.Info("checking watched articles", "store", store.Name(), "articles", len(urls))
+		// Only the failures of the current check are kept
+		store.ResetFailures()
+		products, err := store.GetProductsDetail(urls)
+		if failures := store.GetFailures(); len(failures) > 0 {
+			slog.Warn("watched articles failed to crawl", "failures", len(failures))
+		}
+		if err != nil {
+			slog.Error("error at crawling watched articles", "cause", err)
+		} else {
+			w.check(store.Name(), products, time.Now())
+			if err := w.save(); err != nil {
+				slog.Error("error at saving watch state", "cause", err)
+			}
+		}
+
+		if w.options.Once {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(w.options.Interval):
+		}
+	}
+}
+
+// check alerts the sizes whose availability changed since the previous check
+func (w *watcher) check(store string, products []dto.Product, now time.Time) {
+	for _, alert := range w.state.update(store, products, now, w.options.Cooldown) {
+		w.alert(alert)
+	}
+}
+
+// update records the availability of the sizes of the products, and returns the alerts of the sizes
+// whose availability changed, except those alerted with the same kind within the cooldown.
+// The first sighting of an article only records its availability
+func (s state) update(store string, products []dto.Product, now time.Time, cooldown time.Duration) []dto.StockAlert {
+	var alerts []dto.StockAlert
+	for _, product := range products {
+		sizes, known := s[product.ArticleCode]
+		if !known {
+			sizes = map[string]*sizeState{}
+			s[product.ArticleCode] = sizes
+		}
+
+		for _, sku := range product.Skus {
+			inStock := sku.Status.IsStockEc && !sku.Status.IsSoldOut
+
+			size, ok := sizes[sku.SizeName]
+			if !ok {
+				size = &sizeState{InStock: inStock}
+				sizes[sku.SizeName] = size
+				// A new size of a known article is alerted when it comes in stock
+				if !known || !inStock {
+					continue
+				}
+			} else if size.InStock == inStock {
+				continue
+			}
+			size.InStock = inStock
+
+			kind := dto.StockAlertSoldOut
+			if inStock {
+				kind = dto.StockAlertRestock
+			}
+			if alertedAt, ok := size.AlertedAt[kind]; ok && now.Sub(alertedAt) < cooldown {
+				slog.Info("stock alert suppressed", "kind", kind, "article", product.ArticleCode, "size", sku.SizeName, "alerted_at", alertedAt)
+				continue
+			}
+			if size.AlertedAt == nil {
+				size.AlertedAt = map[dto.StockAlertKind]time.Time{}
+			}
+			size.AlertedAt[kind] = now
+
+			alerts = append(alerts, dto.StockAlert{
+				Kind:         kind,
+				Store:        store,
+				ArticleCode:  product.ArticleCode,
+				Name:         product.Name,
+				URL:          product.URL,
+				SizeName:     sku.SizeName,
+				Status:       sku.Status,
+				StockMessage: sku.StockMessage,
+				At:           now,
+			})
+		}
+	}
+	return alerts
+}
+
+func (w *watcher) alert(alert dto.StockAlert) {
+	for _, alerter := range w.options.Alerters {
+		if err := alerter.Alert(alert); err != nil {
+			slog.Error("error at sending stock alert", "article", alert.ArticleCode, "size", alert.SizeName, "cause", err)
+		}
+	}
+}
+
+func (w *watcher) load() error {
+	if w.options.StateFile == "" {
+		return nil
+	}
+
+	b, err := os.ReadFile(w.options.StateFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(b, &w.state); err != nil {
+		return fmt.Errorf("error at unmarshalling watch state %s: %w", w.options.StateFile, err)
+	}
+	return nil
+}
+
+func (w *watcher) save() error {
+	if w.options.StateFile == "" {
+		return nil
+	}
+
+	b, err := json.MarshalIndent(w.state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(w.options.StateFile, b, 0644)
+}
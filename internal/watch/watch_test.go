@@ -0,0 +1,154 @@
+package watch
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"vcrawler/internal/definition"
+	"vcrawler/internal/dto"
+)
+
+var (
+	inStock = dto.SkuStatus{IsStockEc: true}
+	soldOut = dto.SkuStatus{IsSoldOut: true}
+	// In stock online but flagged sold out, e.g. while the stock is being updated
+	inStockSoldOut = dto.SkuStatus{IsStockEc: true, IsSoldOut: true}
+	storeOnly      = dto.SkuStatus{IsStockStore: true}
+)
+
+func products(sizes map[string]dto.SkuStatus) []dto.Product {
+	product := dto.Product{ArticleCode: "IS8022", Name: "Tiro Track Jacket"}
+	for _, name := range []string{"S", "M", "L"} {
+		if status, ok := sizes[name]; ok {
+			product.Skus = append(product.Skus, dto.Sku{SizeName: name, Status: status})
+		}
+	}
+	return []dto.Product{product}
+}
+
+// alerted is an alert as "<kind> <size>"
+func alerted(alerts []dto.StockAlert) []string {
+	var result []string
+	for _, alert := range alerts {
+		result = append(result, string(alert.Kind)+" "+alert.SizeName)
+	}
+	return result
+}
+
+func TestStateUpdate(t *testing.T) {
+	const cooldown = time.Hour
+	start := time.Date(2024, 9, 1, 10, 0, 0, 0, time.UTC)
+
+	type check struct {
+		after time.Duration // Since the first check
+		sizes map[string]dto.SkuStatus
+		want  []string
+	}
+	tests := []struct {
+		name   string
+		checks []check
+	}{
+		{
+			name: "no alert on the first sighting",
+			checks: []check{
+				{0, map[string]dto.SkuStatus{"S": inStock, "M": soldOut}, nil},
+			},
+		},
+		{
+			name: "restock and sold out",
+			checks: []check{
+				{0, map[string]dto.SkuStatus{"S": inStock, "M": soldOut}, nil},
+				{time.Minute, map[string]dto.SkuStatus{"S": soldOut, "M": inStock}, []string{"sold_out S", "restock M"}},
+			},
+		},
+		{
+			name: "in stock online and not flagged sold out",
+			checks: []check{
+				{0, map[string]dto.SkuStatus{"S": inStock, "M": inStock}, nil},
+				{time.Minute, map[string]dto.SkuStatus{"S": inStockSoldOut, "M": storeOnly}, []string{"sold_out S", "sold_out M"}},
+				{2 * time.Minute, map[string]dto.SkuStatus{"S": inStockSoldOut, "M": storeOnly}, nil},
+			},
+		},
+		{
+			name: "suppressed within the cooldown",
+			checks: []check{
+				{0, map[string]dto.SkuStatus{"S": soldOut}, nil},
+				{time.Minute, map[string]dto.SkuStatus{"S": inStock}, []string{"restock S"}},
+				{2 * time.Minute, map[string]dto.SkuStatus{"S": soldOut}, []string{"sold_out S"}},
+				// Restocked again 29 minutes after the restock alert
+				{30 * time.Minute, map[string]dto.SkuStatus{"S": inStock}, nil},
+				{40 * time.Minute, map[string]dto.SkuStatus{"S": soldOut}, nil},
+				// The cooldown of each kind has passed
+				{2 * time.Hour, map[string]dto.SkuStatus{"S": inStock}, []string{"restock S"}},
+			},
+		},
+		{
+			name: "new size of a known article",
+			checks: []check{
+				{0, map[string]dto.SkuStatus{"S": inStock}, nil},
+				{time.Minute, map[string]dto.SkuStatus{"S": inStock, "M": inStock, "L": soldOut}, []string{"restock M"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := state{}
+			for i, c := range tt.checks {
+				got := alerted(s.update("adidas", products(c.sizes), start.Add(c.after), cooldown))
+				if !slices.Equal(got, c.want) {
+					t.Errorf("check %d: alerts = %q, want %q", i, got, c.want)
+				}
+			}
+		})
+	}
+}
+
+type recordingAlerter struct {
+	alerts []dto.StockAlert
+}
+
+func (a *recordingAlerter) Alert(alert dto.StockAlert) error {
+	a.alerts = append(a.alerts, alert)
+	return nil
+}
+
+func TestWatcherStateFileRestart(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), DefaultStateFile)
+	now := time.Date(2024, 9, 1, 10, 0, 0, 0, time.UTC)
+
+	restart := func() (*watcher, *recordingAlerter) {
+		alerter := &recordingAlerter{}
+		w := GetWatcher(Options{Articles: []string{"IS8022"}, Cooldown: time.Hour, StateFile: stateFile, Alerters: []definition.Alerter{alerter}}).(*watcher)
+		if err := w.load(); err != nil {
+			t.Fatalf("load() error = %v", err)
+		}
+		return w, alerter
+	}
+
+	w, alerter := restart()
+	w.check("adidas", products(map[string]dto.SkuStatus{"S": soldOut}), now)
+	w.check("adidas", products(map[string]dto.SkuStatus{"S": inStock}), now.Add(time.Minute))
+	if err := w.save(); err != nil {
+		t.Fatalf("save() error = %v", err)
+	}
+	if got := alerted(alerter.alerts); !slices.Equal(got, []string{"restock S"}) {
+		t.Fatalf("alerts before restart = %q, want [restock S]", got)
+	}
+
+	// The same availability after a restart isn't alerted again
+	w, alerter = restart()
+	w.check("adidas", products(map[string]dto.SkuStatus{"S": inStock}), now.Add(2*time.Minute))
+	if len(alerter.alerts) > 0 {
+		t.Errorf("alerts after restart = %q, want none", alerted(alerter.alerts))
+	}
+
+	// Nor is a change within the cooldown of the alert before the restart
+	w.check("adidas", products(map[string]dto.SkuStatus{"S": soldOut}), now.Add(3*time.Minute))
+	w.check("adidas", products(map[string]dto.SkuStatus{"S": inStock}), now.Add(4*time.Minute))
+	if got := alerted(alerter.alerts); !slices.Equal(got, []string{"sold_out S"}) {
+		t.Errorf("alerts after restart = %q, want [sold_out S]", got)
+	}
+}